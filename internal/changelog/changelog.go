@@ -0,0 +1,129 @@
+// Package changelog locates and parses Markdown ChangeLog files.
+package changelog
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/russross/blackfriday/v2"
+)
+
+// CommonNames lists the file names looked up when no ChangeLog is given
+var CommonNames = []string{
+	"CHANGELOG.md",
+	"ChangeLog.md",
+	"Changelog.md",
+	"changelog.md",
+	"HISTORY.md",
+	"History.md",
+	"history.md",
+	"NEWS.md",
+	"News.md",
+	"news.md",
+	"RELEASES.md",
+	"Releases.md",
+	"releases.md",
+}
+
+// Resolve returns the given filename or, if empty, the first of the
+// CommonNames found in the given directory, relative to it. Progress is
+// written to w
+func Resolve(w io.Writer, dir, filename string) (string, error) {
+	if filename == "" {
+		fmt.Fprintf(w, "\nLooking for a ChangeLog file\n")
+
+		for _, fn := range CommonNames {
+			_, err := os.Stat(filepath.Join(dir, fn))
+			if os.IsNotExist(err) {
+				continue
+			}
+			fmt.Fprintf(w, "\tFound filename: %v\n", fn)
+			filename = fn
+			break
+		}
+
+		if filename == "" {
+			return filename, fmt.Errorf("\tUnable to find ChangeLog")
+		}
+	}
+	return filename, nil
+}
+
+// Message returns the first paragraph following the heading that refers to
+// the given version in the ChangeLog file. Progress is written to w
+func Message(w io.Writer, v version.Version, filename string) (msg string) {
+	file, err := os.Open(filename)
+	if err != nil {
+		return
+	}
+	defer file.Close()
+
+	var bv []byte
+	bv, err = ioutil.ReadAll(file)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(w, "\nParsing %v...\n", filename)
+	md := blackfriday.New()
+
+	var node *blackfriday.Node
+	if node = md.Parse(bv); node == nil {
+		err = errors.New("Error parsing Markdown")
+		return
+	}
+
+	node.Walk(func(n *blackfriday.Node, e bool) blackfriday.WalkStatus {
+		if e && n.Type == blackfriday.Heading && n.FirstChild != nil && n.Next != nil && n.Next.Type == blackfriday.Paragraph {
+			if strings.Contains(string(n.FirstChild.Literal), v.StringNoV()) {
+				msg = string(n.Next.FirstChild.Literal)
+				return blackfriday.Terminate
+			}
+		}
+		return blackfriday.GoToNext
+	})
+
+	return
+}
+
+// Section returns the contents of the ChangeLog section whose heading refers
+// to the given version
+func Section(bv []byte, v version.Version) (section string, ok bool) {
+	re := regexp.MustCompile(`(^|[^0-9A-Za-z.\-+])v?` + regexp.QuoteMeta(v.StringNoV()) + `($|[^0-9A-Za-z.\-+])`)
+
+	level := 0
+	var lines []string
+	for _, l := range strings.Split(string(bv), "\n") {
+		hl := headingLevel(l)
+		if ok {
+			if hl > 0 && hl <= level {
+				break
+			}
+			lines = append(lines, l)
+			continue
+		}
+
+		if hl > 0 && re.MatchString(strings.TrimLeft(l, "# ")) {
+			ok = true
+			level = hl
+		}
+	}
+
+	section = strings.TrimSpace(strings.Join(lines, "\n"))
+	return
+}
+
+func headingLevel(l string) int {
+	n := len(l) - len(strings.TrimLeft(l, "#"))
+	if n == 0 || n > 6 || (len(l) > n && l[n] != ' ') {
+		return 0
+	}
+	return n
+}
@@ -1,47 +1,151 @@
 package config
 
 import (
-	"encoding/json"
-	"errors"
-	"io/ioutil"
+	"fmt"
 	"os"
+	"path"
 	"path/filepath"
+	"slices"
 
-	"github.com/jwmwalrus/bnp/git"
-	"github.com/jwmwalrus/bnp/onerror"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
 )
 
 const (
-	// Filename names the config file
+	// Filename names the config file in JSON format, the default one
 	Filename = ".bumpy-ride"
 )
 
 // Config defines the bumpy-ride configuration file
 type Config struct {
-	NoFetch       bool        `json:"noFetch"`
-	NoCommit      bool        `json:"noCommit"`
-	VersionPrefix string      `json:"versionPrefix"`
-	NPMPrefixes   []string    `json:"npmPrefixes"`
-	Git           git.Handler `json:"-"`
+	SchemaVersion  int        `json:"schemaVersion" yaml:"schemaVersion" toml:"schemaVersion"`
+	NoFetch        bool       `json:"noFetch" yaml:"noFetch" toml:"noFetch"`
+	NoCommit       bool       `json:"noCommit" yaml:"noCommit" toml:"noCommit"`
+	VersionPrefix  string     `json:"versionPrefix" yaml:"versionPrefix" toml:"versionPrefix"`
+	NPMPrefixes    []string   `json:"npmPrefixes" yaml:"npmPrefixes" toml:"npmPrefixes"`
+	CargoPrefixes  []string   `json:"cargoPrefixes,omitempty" yaml:"cargoPrefixes,omitempty" toml:"cargoPrefixes,omitempty"`
+	MavenPrefixes  []string   `json:"mavenPrefixes,omitempty" yaml:"mavenPrefixes,omitempty" toml:"mavenPrefixes,omitempty"`
+	GradlePrefixes []string   `json:"gradlePrefixes,omitempty" yaml:"gradlePrefixes,omitempty" toml:"gradlePrefixes,omitempty"`
+	Generate       []Generate `json:"generate,omitempty" yaml:"generate,omitempty" toml:"generate,omitempty"`
+	LDFlags        *LDFlags   `json:"ldflags,omitempty" yaml:"ldflags,omitempty" toml:"ldflags,omitempty"`
+	Backend        string     `json:"vcs,omitempty" yaml:"vcs,omitempty" toml:"vcs,omitempty"`
+	Profiles       []Profile  `json:"profiles,omitempty" yaml:"profiles,omitempty" toml:"profiles,omitempty"`
+	VCS            vcs.VCS    `json:"-" yaml:"-" toml:"-"`
+
+	// Branch is the current branch, as detected by Load
+	Branch string `json:"-" yaml:"-" toml:"-"`
+
+	// Profile is the profile matching Branch, if any, as applied by Load
+	Profile *Profile `json:"-" yaml:"-" toml:"-"`
+
+	// Dir is the directory of the configuration file, to which every
+	// configured path is relative
+	Dir string `json:"-" yaml:"-" toml:"-"`
+
+	// File is the name of the configuration file, one of Filenames
+	File string `json:"-" yaml:"-" toml:"-"`
+
+	fileSchemaVersion int
+
+	// values and origins are the merged raw values and their origin, by
+	// dotted key, as loaded by Load
+	values  map[string]any
+	origins map[string]string
 }
 
-// New returns an initial Config
+// ErrNotFound is returned by Load when there is no configuration file
+var ErrNotFound = fmt.Errorf("No config file found, please run `bumpy init`: %w", os.ErrNotExist)
+
+// OpenVCS opens the VCS of the repository containing the given directory,
+// with the given backend. It can be replaced, e.g., with a vcs.Fake for
+// testing purposes
+var OpenVCS = vcs.Open
+
+// Templates lists the built-in templates of Generate
+var Templates = []string{"go", "c", "ts", "python"}
+
+// Generate defines a version source file to be generated on every bump
+type Generate struct {
+	// Target is the path of the file to generate
+	Target string `json:"target" yaml:"target" toml:"target"`
+
+	// Template is either the name of a built-in template (`go`, `c`, `ts`
+	// or `python`) or the path to a text/template file
+	Template string `json:"template" yaml:"template" toml:"template"`
+
+	// Package is the package name, for the `go` template. Defaults to the
+	// name of the target's directory
+	Package string `json:"package,omitempty" yaml:"package,omitempty" toml:"package,omitempty"`
+}
+
+// LDFlagsDisabled, given as the name of a variable in LDFlags, disables it
+const LDFlagsDisabled = "-"
+
+// LDFlags defines the fully-qualified names of the variables set through the
+// `-ldflags -X` options printed by the `ldflags` command. Each variable
+// left empty takes its default, whereas LDFlagsDisabled omits it
+type LDFlags struct {
+	Version string `json:"version,omitempty" yaml:"version,omitempty" toml:"version,omitempty"`
+	Commit  string `json:"commit,omitempty" yaml:"commit,omitempty" toml:"commit,omitempty"`
+	Date    string `json:"date,omitempty" yaml:"date,omitempty" toml:"date,omitempty"`
+}
+
+// New returns an initial Config, without VCS
 func New() *Config {
 	cfg := &Config{}
-	cfg.gitLoad()
 
+	cfg.SchemaVersion = SchemaVersion
+	cfg.fileSchemaVersion = SchemaVersion
+	cfg.File = Filename
 	cfg.VersionPrefix = "."
 	cfg.NPMPrefixes = []string{}
+	cfg.CargoPrefixes = []string{}
+	cfg.MavenPrefixes = []string{}
+	cfg.GradlePrefixes = []string{}
 
 	return cfg
 }
 
-// Load loads the configuration file, which must exist
-func Load() (cfg *Config, err error) {
-	cfg = &Config{}
+// Load loads and validates the effective configuration closest to the
+// current directory. See Find and LoadFrom
+func Load(flags ...Flag) (cfg *Config, err error) {
+	dir, err := Find(".")
+	if err != nil {
+		return
+	}
+	return LoadFrom(dir, flags...)
+}
+
+// LoadFrom loads and validates the effective configuration of the given
+// directory: its configuration file, which must exist, layered over the
+// built-in defaults and the user-level config file, and overridden by the
+// BUMPY_* environment variables and, last, by the given command-line flags
+func LoadFrom(dir string, flags ...Flag) (cfg *Config, err error) {
+	if cfg, err = loadLayered(dir, flags); err != nil {
+		return
+	}
+
+	if err = cfg.Validate(); err != nil {
+		cfg = nil
+	}
+	return
+}
+
+// LoadUnchecked loads the configuration file closest to the current
+// directory alone, which must exist, without validating its values. Unlike
+// Load, no other layer is applied, so the result can be saved back
+func LoadUnchecked() (cfg *Config, err error) {
+	dir, err := Find(".")
+	if err != nil {
+		return
+	}
+
+	if cfg, err = open(dir); err != nil {
+		return
+	}
 
-	if _, err = os.Stat(filepath.Join(".", Filename)); os.IsNotExist(err) {
+	if cfg.File == "" {
 		cfg = nil
+		err = ErrNotFound
 		return
 	}
 
@@ -50,16 +154,33 @@ func Load() (cfg *Config, err error) {
 		return
 	}
 
-	cfg.gitLoad()
+	if err = cfg.vcsLoad(); err != nil {
+		cfg = nil
+	}
 	return
 }
 
-// LoadOrCreate loads the configuration file if it exists, or creates it otherwise
+// LoadOrCreate loads the configuration file of the current directory alone,
+// if it exists, or creates it otherwise. See LoadOrCreateFrom
 func LoadOrCreate() (cfg *Config, created bool, err error) {
-	cfg = &Config{}
+	return LoadOrCreateFrom(".")
+}
+
+// LoadOrCreateFrom loads the configuration file of the given directory
+// alone, if it exists, or creates it otherwise
+func LoadOrCreateFrom(dir string) (cfg *Config, created bool, err error) {
+	if cfg, err = open(dir); err != nil {
+		return
+	}
 
-	if _, err = os.Stat(filepath.Join(".", Filename)); errors.Is(err, os.ErrNotExist) {
+	if cfg.File == "" {
+		dir := cfg.Dir
 		cfg = New()
+		cfg.Dir = dir
+		if err = cfg.vcsLoad(); err != nil {
+			cfg = nil
+			return
+		}
 		if err = cfg.Save(); err != nil {
 			cfg = nil
 			return
@@ -73,47 +194,141 @@ func LoadOrCreate() (cfg *Config, created bool, err error) {
 		return
 	}
 
-	cfg.gitLoad()
+	if err = cfg.vcsLoad(); err != nil {
+		cfg = nil
+	}
+	return
+}
+
+// open returns an empty configuration for the given directory, naming its
+// configuration file, if any
+func open(dir string) (cfg *Config, err error) {
+	if dir, err = filepath.Abs(dir); err != nil {
+		return
+	}
+
+	cfg = &Config{Dir: dir, File: locate(dir)}
 	return
 }
 
 // Read reads the configuration file
 func (cfg *Config) Read() (err error) {
-	bv, err := GetBytes()
+	bv, err := os.ReadFile(cfg.Path(cfg.File))
 	if err != nil {
 		return
 	}
 
-	err = json.Unmarshal(bv, cfg)
+	if bv, err = toJSON(cfg.File, bv); err != nil {
+		return
+	}
+
+	if err = cfg.decode(bv); err != nil {
+		return
+	}
+
+	if cfg.NPMPrefixes == nil {
+		cfg.NPMPrefixes = []string{}
+	}
 	return
 }
 
-// Save writes the configuration file
+// Save writes the configuration file, in its format and with the current
+// schema version
 func (cfg *Config) Save() (err error) {
-	bv, err := json.MarshalIndent(*cfg, "", "  ")
+	cfg.SchemaVersion = SchemaVersion
+
+	bv, err := cfg.Marshal()
 	if err != nil {
 		return
 	}
 
-	err = ioutil.WriteFile(filepath.Join(".", Filename), bv, 0644)
+	if cfg.File == PackageJSON {
+		var pkg []byte
+		if pkg, err = os.ReadFile(cfg.Path(PackageJSON)); err != nil {
+			return
+		}
+		if bv, err = setPackageConfig(pkg, bv); err != nil {
+			return
+		}
+	}
+
+	err = os.WriteFile(cfg.Path(cfg.File), bv, 0644)
 	return
 }
 
-func (cfg *Config) gitLoad() {
-	cwd, _ := os.Getwd()
-	var err error
-	cfg.Git, err = git.NewHandler(cwd)
-	onerror.Fatal(err)
+func (cfg *Config) vcsLoad() (err error) {
+	// unsupported backends are reported by Validate
+	backend := cfg.Backend
+	if !slices.Contains(vcs.Backends, backend) {
+		backend = vcs.BackendAuto
+	}
+
+	cfg.VCS, err = OpenVCS(cfg.Dir, backend)
+	return
 }
 
-// GetBytes reads the configuration file and returns its bytes
-func GetBytes() (bv []byte, err error) {
-	jsonFile, err := os.Open(filepath.Join(".", Filename))
-	if err != nil {
+// Find returns the directory of the configuration file closest to dir,
+// looking upwards up to the top-level directory of the repository. If there
+// is none, the top-level directory is returned or, outside of a repository,
+// dir itself
+func Find(dir string) (found string, err error) {
+	if dir, err = filepath.Abs(dir); err != nil {
+		return
+	}
+
+	root := vcs.Root(dir)
+	if root == "" {
+		found = dir
 		return
 	}
-	defer jsonFile.Close()
 
-	bv, err = ioutil.ReadAll(jsonFile)
+	for found = dir; ; found = filepath.Dir(found) {
+		if locate(found) != "" {
+			return
+		}
+		if found == root || found == filepath.Dir(found) {
+			break
+		}
+	}
+
+	found = root
 	return
 }
+
+// RepoPath returns the given path, relative to Dir unless absolute, as a
+// slash-separated path relative to the top-level directory of the
+// repository, as used by the VCS
+func (cfg *Config) RepoPath(p string) string {
+	top, dir := cfg.VCS.TopLevel(), cfg.Dir
+	if filepath.IsAbs(p) {
+		dir, p = p, ""
+	}
+
+	if s, err := filepath.EvalSymlinks(top); err == nil {
+		top = s
+	}
+	if s, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = s
+	}
+
+	rel, err := filepath.Rel(top, filepath.Join(dir, p))
+	if err != nil {
+		return path.Clean(filepath.ToSlash(p))
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Path returns the given path, relative to Dir, as a path usable regardless
+// of the current directory
+func (cfg *Config) Path(p string) string {
+	if filepath.IsAbs(p) {
+		return p
+	}
+	return filepath.Join(cfg.Dir, p)
+}
+
+// HasVCS tells whether the current directory is in a repository
+func (cfg *Config) HasVCS() bool {
+	_, none := cfg.VCS.(*vcs.None)
+	return cfg.VCS != nil && !none
+}
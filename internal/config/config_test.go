@@ -0,0 +1,66 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestFind(t *testing.T) {
+	dir, _ := newConfigDir(t, map[string]string{
+		".git/HEAD":              "",
+		Filename:                 "{}",
+		"web/package.json":       `{"name": "web", "bumpy": {}}`,
+		"web/src/index.ts":       "",
+		"lib/package.json":       `{"name": "lib"}`,
+		"lib/src/lib.go":         "",
+		"tools/.bumpy-ride.yaml": "",
+	})
+
+	outside, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	bare, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Join(bare, ".hg"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.MkdirAll(filepath.Join(bare, "a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name string
+		dir  string
+		want string
+	}{
+		{"top-level", dir, dir},
+		{"package.json section", filepath.Join(dir, "web", "src"), filepath.Join(dir, "web")},
+		{"package.json without section", filepath.Join(dir, "lib", "src"), dir},
+		{"nested config", filepath.Join(dir, "tools"), filepath.Join(dir, "tools")},
+		{"outside of a repository", outside, outside},
+		{"repository without config", filepath.Join(bare, "a", "b"), bare},
+	}
+
+	for _, tt := range tests {
+		if got, err := Find(tt.dir); err != nil || got != tt.want {
+			t.Errorf("%v: found %v (%v), want %v", tt.name, got, err, tt.want)
+		}
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	if err = os.Chdir(filepath.Join(dir, "lib", "src")); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := Find("."); err != nil || got != dir {
+		t.Errorf("found %v (%v) from the current directory, want %v", got, err, dir)
+	}
+}
@@ -0,0 +1,175 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	// YAMLFilename names the config file in YAML format
+	YAMLFilename = ".bumpy-ride.yaml"
+
+	// TOMLFilename names the config file in TOML format
+	TOMLFilename = ".bumpy-ride.toml"
+
+	// PackageJSON names the npm manifest, whose `bumpy` section holds the
+	// configuration
+	PackageJSON = "package.json"
+
+	// packageSection is the key of the configuration in PackageJSON
+	packageSection = "bumpy"
+)
+
+// Filenames lists the supported config files, by precedence
+var Filenames = []string{Filename, YAMLFilename, TOMLFilename, PackageJSON}
+
+// locate returns the name of the config file with the highest precedence in
+// the given directory, or an empty string if there is none
+func locate(dir string) string {
+	for _, name := range Filenames {
+		bv, err := os.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			continue
+		}
+
+		if name == PackageJSON {
+			if _, err = packageConfig(bv); err != nil {
+				continue
+			}
+		}
+
+		return name
+	}
+	return ""
+}
+
+// toJSON converts the contents of the given config file to JSON
+func toJSON(name string, bv []byte) ([]byte, error) {
+	switch name {
+	case YAMLFilename:
+		return yamlToJSON(name, bv)
+	case TOMLFilename:
+		raw := map[string]any{}
+		if err := toml.Unmarshal(bv, &raw); err != nil {
+			return nil, fmt.Errorf("Invalid TOML in %v: %w", name, err)
+		}
+		return json.Marshal(raw)
+	case PackageJSON:
+		return packageConfig(bv)
+	}
+	return bv, nil
+}
+
+// yamlToJSON converts the given YAML contents, which may as well be JSON, to
+// JSON
+func yamlToJSON(name string, bv []byte) ([]byte, error) {
+	raw := map[string]any{}
+	if err := yaml.Unmarshal(bv, &raw); err != nil {
+		return nil, fmt.Errorf("Invalid YAML in %v: %w", name, err)
+	}
+	return json.Marshal(raw)
+}
+
+// Marshal returns the configuration in the format of its file. For
+// PackageJSON, only the `bumpy` section is returned
+func (cfg *Config) Marshal() (bv []byte, err error) {
+	switch cfg.File {
+	case YAMLFilename:
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err = enc.Encode(cfg); err != nil {
+			return
+		}
+		err = enc.Close()
+		bv = buf.Bytes()
+	case TOMLFilename:
+		var buf bytes.Buffer
+		enc := toml.NewEncoder(&buf)
+		enc.Indent = ""
+		err = enc.Encode(cfg)
+		bv = buf.Bytes()
+	default:
+		bv, err = json.MarshalIndent(*cfg, "", "  ")
+	}
+	return
+}
+
+// packageConfig returns the `bumpy` section of the given package.json
+func packageConfig(bv []byte) ([]byte, error) {
+	pkg := map[string]json.RawMessage{}
+	if err := json.Unmarshal(bv, &pkg); err != nil {
+		return nil, fmt.Errorf("Invalid JSON in %v: %w", PackageJSON, err)
+	}
+
+	section, ok := pkg[packageSection]
+	if !ok {
+		return nil, fmt.Errorf("No `%v` section in %v", packageSection, PackageJSON)
+	}
+	return section, nil
+}
+
+// setPackageConfig returns the given package.json with its `bumpy` section
+// replaced, keeping the order of the other keys
+func setPackageConfig(bv, section []byte) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(bv))
+	if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+		return nil, fmt.Errorf("Invalid JSON in %v: not an object", PackageJSON)
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+
+	found := false
+	for dec.More() {
+		t, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("Invalid JSON in %v: %w", PackageJSON, err)
+		}
+
+		key, ok := t.(string)
+		if !ok {
+			return nil, errors.New("Invalid JSON in " + PackageJSON)
+		}
+
+		var value json.RawMessage
+		if err = dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("Invalid JSON in %v: %w", PackageJSON, err)
+		}
+
+		if key == packageSection {
+			value, found = section, true
+		}
+		writeMember(&buf, key, value)
+	}
+
+	if !found {
+		writeMember(&buf, packageSection, section)
+	}
+	buf.WriteByte('}')
+
+	var out bytes.Buffer
+	if err := json.Indent(&out, buf.Bytes(), "", "  "); err != nil {
+		return nil, err
+	}
+	out.WriteByte('\n')
+	return out.Bytes(), nil
+}
+
+func writeMember(buf *bytes.Buffer, key string, value []byte) {
+	if buf.Len() > 1 {
+		buf.WriteByte(',')
+	}
+
+	k, _ := json.Marshal(key)
+	buf.Write(k)
+	buf.WriteByte(':')
+	buf.Write(value)
+}
@@ -0,0 +1,149 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocate(t *testing.T) {
+	const (
+		pkg     = `{"name": "app", "version": "1.0.0"}`
+		pkgConf = `{"name": "app", "bumpy": {"noFetch": true}}`
+	)
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		want  string
+	}{
+		{"none", nil, ""},
+		{"json first", map[string]string{Filename: "{}", YAMLFilename: "{}", TOMLFilename: "", PackageJSON: pkgConf}, Filename},
+		{"yaml over toml", map[string]string{YAMLFilename: "{}", TOMLFilename: ""}, YAMLFilename},
+		{"toml over package.json", map[string]string{TOMLFilename: "", PackageJSON: pkgConf}, TOMLFilename},
+		{"package.json section", map[string]string{PackageJSON: pkgConf}, PackageJSON},
+		{"package.json without section", map[string]string{PackageJSON: pkg}, ""},
+		{"invalid package.json", map[string]string{PackageJSON: "{"}, ""},
+	}
+
+	for _, tt := range tests {
+		dir, _ := newConfigDir(t, tt.files)
+		if got := locate(dir); got != tt.want {
+			t.Errorf("%v: located %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestFormats(t *testing.T) {
+	tests := []struct {
+		file    string
+		content string
+	}{
+		{Filename, `{"noFetch": true, "npmPrefixes": ["web"], "ldflags": {"version": "main.V"}}`},
+		{YAMLFilename, "noFetch: true\nnpmPrefixes:\n  - web\nldflags:\n  version: main.V\n"},
+		{YAMLFilename, `{"noFetch": true, "npmPrefixes": ["web"], "ldflags": {"version": "main.V"}}`},
+		{TOMLFilename, "noFetch = true\nnpmPrefixes = [\"web\"]\n\n[ldflags]\nversion = \"main.V\"\n"},
+		{PackageJSON, `{"name": "app", "bumpy": {"noFetch": true, "npmPrefixes": ["web"], "ldflags": {"version": "main.V"}}, "version": "1.0.0"}`},
+	}
+
+	for _, tt := range tests {
+		dir, _ := newConfigDir(t, map[string]string{tt.file: tt.content})
+
+		// read, saved in the same format, and read back
+		for i := 0; i < 2; i++ {
+			cfg, err := open(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if cfg.File != tt.file {
+				t.Fatalf("%v: located %v", tt.file, cfg.File)
+			}
+			if err = cfg.Read(); err != nil {
+				t.Fatalf("%v: %v", tt.file, err)
+			}
+
+			got := fmt.Sprintf("%v %v %+v", cfg.NoFetch, cfg.NPMPrefixes, *cfg.LDFlags)
+			if want := "true [web] {Version:main.V Commit: Date:}"; got != want {
+				t.Errorf("%v (pass %v): read %v, want %v", tt.file, i+1, got, want)
+			}
+
+			if err = cfg.Save(); err != nil {
+				t.Fatalf("%v: %v", tt.file, err)
+			}
+		}
+
+		bv, err := os.ReadFile(filepath.Join(dir, tt.file))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if s := string(bv); !strings.Contains(s, "schemaVersion") {
+			t.Errorf("%v: schemaVersion not saved:\n%v", tt.file, s)
+		}
+	}
+}
+
+func TestFormatErrors(t *testing.T) {
+	for _, tt := range []struct{ file, content, want string }{
+		{YAMLFilename, "noFetch: [\n", "Invalid YAML in " + YAMLFilename},
+		{TOMLFilename, "noFetch = \n", "Invalid TOML in " + TOMLFilename},
+	} {
+		dir, _ := newConfigDir(t, map[string]string{tt.file: tt.content})
+
+		cfg, err := open(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = cfg.Read(); err == nil || !strings.Contains(err.Error(), tt.want) {
+			t.Errorf("%v: got %v, want %q", tt.file, err, tt.want)
+		}
+	}
+}
+
+func TestPackageConfig(t *testing.T) {
+	if _, err := packageConfig([]byte(`{"name": "app"}`)); err == nil || !strings.Contains(err.Error(), "No `bumpy` section") {
+		t.Errorf("got %v, want a missing section error", err)
+	}
+	if _, err := packageConfig([]byte(`[]`)); err == nil {
+		t.Error("expected error for a non-object package.json")
+	}
+
+	bv, err := packageConfig([]byte(`{"name": "app", "bumpy": {"noFetch": true}}`))
+	if err != nil || string(bv) != `{"noFetch": true}` {
+		t.Errorf("section = %s (%v)", bv, err)
+	}
+}
+
+func TestSetPackageConfig(t *testing.T) {
+	tests := []struct {
+		name string
+		pkg  string
+		want string
+	}{
+		{
+			"replaced in place",
+			`{"name": "app", "bumpy": {"noFetch": false}, "version": "1.0.0", "scripts": {"z": "1", "a": "2"}}`,
+			"{\n  \"name\": \"app\",\n  \"bumpy\": {\n    \"noFetch\": true\n  },\n  \"version\": \"1.0.0\",\n  \"scripts\": {\n    \"z\": \"1\",\n    \"a\": \"2\"\n  }\n}\n",
+		},
+		{
+			"appended",
+			`{"version": "1.0.0", "name": "app"}`,
+			"{\n  \"version\": \"1.0.0\",\n  \"name\": \"app\",\n  \"bumpy\": {\n    \"noFetch\": true\n  }\n}\n",
+		},
+	}
+
+	for _, tt := range tests {
+		bv, err := setPackageConfig([]byte(tt.pkg), []byte(`{"noFetch": true}`))
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if string(bv) != tt.want {
+			t.Errorf("%v:\n%s\nwant\n%s", tt.name, bv, tt.want)
+		}
+	}
+
+	if _, err := setPackageConfig([]byte(`[1]`), []byte(`{}`)); err == nil {
+		t.Error("expected error for a non-object package.json")
+	}
+}
@@ -0,0 +1,332 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	// OriginDefault is the origin of the built-in default values
+	OriginDefault = "default"
+
+	// EnvPrefix prefixes the environment variables overriding the
+	// configuration, e.g., BUMPY_NO_FETCH for `noFetch`
+	EnvPrefix = "BUMPY_"
+)
+
+// Setting is an effective configuration value, along with its origin
+type Setting struct {
+	Key    string `json:"key"`
+	Value  any    `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// Flag is a command-line flag setting a configuration key, overriding every
+// other layer
+type Flag struct {
+	// Name is the flag as given, e.g., `--no-commit`
+	Name string
+
+	// Key is the dot-separated key it sets, e.g., `noCommit`
+	Key   string
+	Value any
+}
+
+// layer is a source of configuration values, overriding those of the
+// previous layers
+type layer struct {
+	// name is the file, the environment variable or the flag the values come
+	// from
+	name   string
+	origin string
+	raw    map[string]any
+}
+
+// UserFile returns the path of the user-level config file,
+// `$XDG_CONFIG_HOME/bumpy/config`, or an empty string if it cannot be
+// determined
+func UserFile() string {
+	dir := os.Getenv("XDG_CONFIG_HOME")
+	if dir == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		dir = filepath.Join(home, ".config")
+	}
+	return filepath.Join(dir, "bumpy", "config")
+}
+
+// loadLayered loads the configuration file of the given directory, which
+// must exist, on top of the built-in defaults and the user-level config file,
+// and below the BUMPY_* environment variables and the given flags
+func loadLayered(dir string, flags []Flag) (cfg *Config, err error) {
+	if cfg, err = open(dir); err != nil {
+		return
+	}
+
+	if cfg.File == "" {
+		cfg = nil
+		err = ErrNotFound
+		return
+	}
+
+	files, from, err := cfg.layers()
+	if err != nil {
+		cfg = nil
+		return
+	}
+
+	env, err := envLayers(reflect.TypeOf(Config{}), EnvPrefix, nil)
+	if err != nil {
+		cfg = nil
+		return
+	}
+
+	top := append(env, flagLayers(flags)...)
+	if err = cfg.apply(append(files, top...)); err != nil {
+		cfg = nil
+		return
+	}
+
+	cfg.SchemaVersion = SchemaVersion
+	cfg.fileSchemaVersion = from
+	if cfg.NPMPrefixes == nil {
+		cfg.NPMPrefixes = []string{}
+	}
+
+	if err = cfg.vcsLoad(); err != nil {
+		cfg = nil
+		return
+	}
+
+	// the profile is applied once the branch is known, right below the
+	// environment variables and the flags
+	cfg.Branch = cfg.detectBranch()
+	if cfg.Profile = cfg.matchProfile(cfg.Branch); cfg.Profile != nil {
+		layers := append(files, cfg.Profile.layer())
+		if err = cfg.apply(append(layers, top...)); err != nil {
+			cfg = nil
+		}
+	}
+	return
+}
+
+// apply decodes the given layers, merged by increasing precedence, into the
+// configuration
+func (cfg *Config) apply(layers []layer) (err error) {
+	merged := map[string]any{}
+	origins := map[string]string{}
+	for _, l := range layers {
+		delete(l.raw, "schemaVersion")
+
+		// decoded on its own, so that errors name the offending layer
+		if err = (&Config{File: l.name}).decodeRaw(l.raw); err != nil {
+			return
+		}
+		merge(merged, l.raw, "", l.origin, origins)
+	}
+
+	if err = cfg.decodeRaw(merged); err != nil {
+		return
+	}
+
+	cfg.values, cfg.origins = merged, origins
+	return
+}
+
+// layers returns the file layers of the configuration, by increasing
+// precedence, along with the schema version of the configuration file
+func (cfg *Config) layers() (layers []layer, from int, err error) {
+	bv, err := json.Marshal(New())
+	if err != nil {
+		return
+	}
+
+	defaults := map[string]any{}
+	if err = json.Unmarshal(bv, &defaults); err != nil {
+		return
+	}
+	layers = append(layers, layer{OriginDefault, OriginDefault, defaults})
+
+	if user := UserFile(); user != "" {
+		if bv, err = os.ReadFile(user); err == nil {
+			var raw map[string]any
+			if bv, err = yamlToJSON(user, bv); err != nil {
+				return
+			}
+			if raw, _, err = upgrade(bv, user); err != nil {
+				return
+			}
+			layers = append(layers, layer{user, "file:" + user, raw})
+		} else if !os.IsNotExist(err) {
+			return
+		}
+	}
+
+	if bv, err = os.ReadFile(cfg.Path(cfg.File)); err != nil {
+		return
+	}
+	if bv, err = toJSON(cfg.File, bv); err != nil {
+		return
+	}
+
+	var raw map[string]any
+	if raw, from, err = upgrade(bv, cfg.File); err != nil {
+		return
+	}
+	layers = append(layers, layer{cfg.File, "file:" + cfg.File, raw})
+	return
+}
+
+// envLayers returns a layer for every environment variable set for the keys
+// of the given struct type. Lists are comma-separated, and nested keys are
+// joined with an underscore, e.g., BUMPY_LDFLAGS_VERSION
+func envLayers(t reflect.Type, prefix string, path []string) (layers []layer, err error) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		key, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if key == "" || key == "-" || key == "schemaVersion" {
+			continue
+		}
+
+		name := prefix + envName(key)
+		keys := append(append([]string{}, path...), key)
+
+		if f.Type.Kind() == reflect.Pointer && f.Type.Elem().Kind() == reflect.Struct {
+			var nested []layer
+			if nested, err = envLayers(f.Type.Elem(), name+"_", keys); err != nil {
+				return
+			}
+			layers = append(layers, nested...)
+			continue
+		}
+
+		s := os.Getenv(name)
+		if s == "" {
+			continue
+		}
+
+		var value any
+		switch {
+		case f.Type.Kind() == reflect.Bool:
+			if value, err = strconv.ParseBool(s); err != nil {
+				err = fmt.Errorf("Invalid value for `%v` in %v: expected bool, got %v", strings.Join(keys, "."), name, strconv.Quote(s))
+				return
+			}
+		case f.Type.Kind() == reflect.String:
+			value = s
+		case f.Type.Kind() == reflect.Slice && f.Type.Elem().Kind() == reflect.String:
+			list := []any{}
+			for _, e := range strings.Split(s, ",") {
+				if e = strings.TrimSpace(e); e != "" {
+					list = append(list, e)
+				}
+			}
+			value = list
+		default:
+			continue
+		}
+
+		raw := map[string]any{keys[len(keys)-1]: value}
+		for j := len(keys) - 2; j >= 0; j-- {
+			raw = map[string]any{keys[j]: raw}
+		}
+		layers = append(layers, layer{name, "env:" + name, raw})
+	}
+	return
+}
+
+// flagLayers returns a layer for every given flag
+func flagLayers(flags []Flag) (layers []layer) {
+	for _, f := range flags {
+		keys := strings.Split(f.Key, ".")
+		raw := map[string]any{keys[len(keys)-1]: f.Value}
+		for j := len(keys) - 2; j >= 0; j-- {
+			raw = map[string]any{keys[j]: raw}
+		}
+		layers = append(layers, layer{f.Name, "flag:" + f.Name, raw})
+	}
+	return
+}
+
+// envName converts the given camel-cased key to upper snake case
+func envName(key string) string {
+	var sb strings.Builder
+	for i, r := range key {
+		if i > 0 && unicode.IsUpper(r) {
+			sb.WriteByte('_')
+		}
+		sb.WriteRune(unicode.ToUpper(r))
+	}
+	return sb.String()
+}
+
+// merge copies the src values into dst, recursing into nested objects, and
+// records the origin of every value copied
+func merge(dst, src map[string]any, prefix, origin string, origins map[string]string) {
+	for k, v := range src {
+		key := prefix + k
+
+		if m, ok := v.(map[string]any); ok {
+			sub, ok := dst[k].(map[string]any)
+			if !ok {
+				sub = map[string]any{}
+				dst[k] = sub
+			}
+			merge(sub, m, key+".", origin, origins)
+			continue
+		}
+
+		dst[k] = v
+		origins[key] = origin
+	}
+}
+
+// Settings returns the effective configuration values along with their
+// origin, in schema order. Only configurations loaded with Load have any
+func (cfg *Config) Settings() (list []Setting) {
+	var walk func(t reflect.Type, values map[string]any, prefix string)
+	walk = func(t reflect.Type, values map[string]any, prefix string) {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		for i := 0; i < t.NumField(); i++ {
+			k, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			v, ok := values[k]
+			if !ok {
+				continue
+			}
+
+			if m, ok := v.(map[string]any); ok {
+				walk(t.Field(i).Type, m, prefix+k+".")
+				continue
+			}
+
+			list = append(list, Setting{
+				Key:    prefix + k,
+				Value:  v,
+				Origin: cfg.origins[prefix+k],
+			})
+		}
+	}
+
+	walk(reflect.TypeOf(*cfg), cfg.values, "")
+	return
+}
+
+// source returns the name of the file, environment variable or flag the given
+// key comes from
+func (cfg *Config) source(key string) string {
+	if _, name, ok := strings.Cut(cfg.origins[key], ":"); ok {
+		return name
+	}
+	return cfg.File
+}
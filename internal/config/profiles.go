@@ -0,0 +1,125 @@
+package config
+
+import (
+	"os"
+	"path"
+	"strings"
+)
+
+// Increments allowed in Profile.Increments
+const (
+	IncrementPatch = "patch"
+	IncrementMinor = "minor"
+	IncrementMajor = "major"
+)
+
+// Profile defines the settings and bump policies of the branches matching
+// Branch
+type Profile struct {
+	// Branch is a glob, as in path.Match, matched against the current
+	// branch, e.g., `release/*`
+	Branch string `json:"branch" yaml:"branch" toml:"branch"`
+
+	// NoFetch and NoCommit, if set, override the settings of the same name
+	NoFetch  *bool `json:"noFetch,omitempty" yaml:"noFetch,omitempty" toml:"noFetch,omitempty"`
+	NoCommit *bool `json:"noCommit,omitempty" yaml:"noCommit,omitempty" toml:"noCommit,omitempty"`
+
+	// Pre, if set, is the prerelease string of every bump, unless one is
+	// given explicitly. `{branch}` is replaced with the name of the branch
+	Pre string `json:"pre,omitempty" yaml:"pre,omitempty" toml:"pre,omitempty"`
+
+	// Increments, if set, lists the only increments allowed: `patch`,
+	// `minor` and/or `major`
+	Increments []string `json:"increments,omitempty" yaml:"increments,omitempty" toml:"increments,omitempty"`
+}
+
+// ciBranchVars lists the environment variables holding the branch being
+// built, by CI provider
+var ciBranchVars = []string{
+	"GITHUB_HEAD_REF",                     // GitHub Actions, pull requests
+	"GITHUB_REF_NAME",                     // GitHub Actions, if GITHUB_REF_TYPE is `branch`
+	"CI_MERGE_REQUEST_SOURCE_BRANCH_NAME", // GitLab CI, merge requests
+	"CI_COMMIT_BRANCH",                    // GitLab CI
+	"BITBUCKET_BRANCH",                    // Bitbucket Pipelines
+	"CIRCLE_BRANCH",                       // CircleCI
+	"TRAVIS_PULL_REQUEST_BRANCH",          // Travis CI, pull requests
+	"TRAVIS_BRANCH",                       // Travis CI
+	"BUILDKITE_BRANCH",                    // Buildkite
+	"DRONE_SOURCE_BRANCH",                 // Drone
+	"CHANGE_BRANCH",                       // Jenkins, pull requests
+	"BRANCH_NAME",                         // Jenkins
+	"BUILD_SOURCEBRANCH",                  // Azure Pipelines
+}
+
+// detectBranch returns the current branch, as reported by the VCS or, in
+// detached HEAD or outside of a repository, by the CI environment variables
+func (cfg *Config) detectBranch() string {
+	if branch, err := cfg.VCS.Branch(); err == nil && branch != "" {
+		return branch
+	}
+
+	for _, name := range ciBranchVars {
+		if name == "GITHUB_REF_NAME" && os.Getenv("GITHUB_REF_TYPE") != "branch" {
+			continue
+		}
+		if branch := os.Getenv(name); branch != "" {
+			return strings.TrimPrefix(branch, "refs/heads/")
+		}
+	}
+	return ""
+}
+
+// matchProfile returns the first profile matching the given branch, if any
+func (cfg *Config) matchProfile(branch string) *Profile {
+	if branch == "" {
+		return nil
+	}
+
+	for _, p := range cfg.Profiles {
+		if ok, _ := path.Match(p.Branch, branch); ok {
+			return &p
+		}
+	}
+	return nil
+}
+
+// layer returns the settings overridden by the profile, as a layer
+func (p *Profile) layer() layer {
+	raw := map[string]any{}
+	if p.NoFetch != nil {
+		raw["noFetch"] = *p.NoFetch
+	}
+	if p.NoCommit != nil {
+		raw["noCommit"] = *p.NoCommit
+	}
+	return layer{p.Branch, "profile:" + p.Branch, raw}
+}
+
+// ProfilePre returns the prerelease string of the current profile, with
+// `{branch}` replaced by the name of the branch as a prerelease identifier
+func (cfg *Config) ProfilePre() string {
+	if cfg.Profile == nil {
+		return ""
+	}
+
+	return strings.ReplaceAll(cfg.Profile.Pre, "{branch}", preIdentifier(cfg.Branch))
+}
+
+// preIdentifier returns the given branch name as a valid SemVer prerelease
+// identifier, where every character not allowed is replaced with a hyphen,
+// and leading zeros are dropped from numeric ones, e.g., `01` -> `1`
+func preIdentifier(branch string) string {
+	id := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r == '-' {
+			return r
+		}
+		return '-'
+	}, branch)
+
+	if strings.Trim(id, "0123456789") == "" {
+		if id = strings.TrimLeft(id, "0"); id == "" {
+			id = "0"
+		}
+	}
+	return id
+}
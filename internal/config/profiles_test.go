@@ -0,0 +1,203 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+)
+
+// newConfigDir writes the given files to a new directory, backed by a
+// vcs.Fake injected through OpenVCS, and isolated from the user-level config,
+// the BUMPY_* environment variables and the CI branch variables
+func newConfigDir(t *testing.T, files map[string]string) (string, *vcs.Fake) {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err = os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err = os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	fake := vcs.NewFake(dir)
+	open := OpenVCS
+	OpenVCS = func(string, string) (vcs.VCS, error) { return fake, nil }
+	t.Cleanup(func() { OpenVCS = open })
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GITHUB_REF_TYPE", "")
+	for _, name := range ciBranchVars {
+		t.Setenv(name, "")
+	}
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, EnvPrefix) {
+			t.Setenv(name, "")
+		}
+	}
+
+	return dir, fake
+}
+
+// originOf returns the value and origin of the given key
+func originOf(cfg *Config, key string) string {
+	for _, s := range cfg.Settings() {
+		if s.Key == key {
+			return fmt.Sprintf("%v from %v", s.Value, s.Origin)
+		}
+	}
+	return ""
+}
+
+func TestDetectBranch(t *testing.T) {
+	tests := []struct {
+		name   string
+		branch string
+		env    map[string]string
+		want   string
+	}{
+		{"vcs", "main", map[string]string{"GITHUB_HEAD_REF": "feature/x"}, "main"},
+		{"detached", "", nil, ""},
+		{"pull request", "", map[string]string{"GITHUB_HEAD_REF": "feature/x", "GITHUB_REF_NAME": "12/merge", "GITHUB_REF_TYPE": "branch"}, "feature/x"},
+		{"github branch", "", map[string]string{"GITHUB_REF_NAME": "release/1.x", "GITHUB_REF_TYPE": "branch"}, "release/1.x"},
+		{"github tag", "", map[string]string{"GITHUB_REF_NAME": "v1.0.0", "GITHUB_REF_TYPE": "tag", "CI_COMMIT_BRANCH": "main"}, "main"},
+		{"github without ref type", "", map[string]string{"GITHUB_REF_NAME": "v1.0.0"}, ""},
+		{"azure", "", map[string]string{"BUILD_SOURCEBRANCH": "refs/heads/feature/y"}, "feature/y"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, fake := newConfigDir(t, nil)
+			fake.CurrentBranch = tt.branch
+			for k, v := range tt.env {
+				t.Setenv(k, v)
+			}
+
+			cfg := &Config{VCS: fake}
+			if got := cfg.detectBranch(); got != tt.want {
+				t.Errorf("branch = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMatchProfile(t *testing.T) {
+	cfg := &Config{Profiles: []Profile{
+		{Branch: "feature/*", Pre: "a"},
+		{Branch: "feature/*/*", Pre: "b"},
+		{Branch: "*", Pre: "c"},
+		{Branch: "main", Pre: "d"},
+	}}
+
+	tests := []struct {
+		branch string
+		want   string
+	}{
+		{"feature/x", "a"},
+		{"feature/x/y", "b"},
+		{"main", "c"},
+		{"fix/x", ""},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		got := ""
+		if p := cfg.matchProfile(tt.branch); p != nil {
+			got = p.Pre
+		}
+		if got != tt.want {
+			t.Errorf("%q: matched %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+}
+
+func TestProfilePre(t *testing.T) {
+	tests := []struct {
+		branch string
+		pre    string
+		want   string
+	}{
+		{"feature/login", "{branch}", "feature-login"},
+		{"fix/v1.2_x", "dev.{branch}", "dev.fix-v1-2-x"},
+		{"01", "{branch}", "1"},
+		{"000", "pr.{branch}", "pr.0"},
+		{"0-x", "{branch}", "0-x"},
+		{"main", "rc", "rc"},
+	}
+
+	for _, tt := range tests {
+		cfg := &Config{Branch: tt.branch, Profile: &Profile{Pre: tt.pre}}
+		if got := cfg.ProfilePre(); got != tt.want {
+			t.Errorf("%q: pre = %q, want %q", tt.branch, got, tt.want)
+		}
+	}
+
+	if got := (&Config{Branch: "x"}).ProfilePre(); got != "" {
+		t.Errorf("pre without profile = %q", got)
+	}
+}
+
+func TestProfileLayer(t *testing.T) {
+	dir, fake := newConfigDir(t, map[string]string{
+		Filename: fmt.Sprintf(`{
+  "schemaVersion": %v,
+  "noFetch": false,
+  "noCommit": false,
+  "profiles": [
+    {"branch": "feature/*", "noFetch": true, "noCommit": true, "pre": "{branch}"}
+  ]
+}`, SchemaVersion),
+	})
+
+	fake.CurrentBranch = "main"
+	cfg, err := LoadFrom(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Profile != nil || originOf(cfg, "noFetch") != "false from file:"+Filename {
+		t.Errorf("unexpected profile %+v, noFetch %v", cfg.Profile, originOf(cfg, "noFetch"))
+	}
+
+	fake.CurrentBranch = ""
+	t.Setenv("CI_COMMIT_BRANCH", "feature/login")
+	t.Setenv("BUMPY_NO_COMMIT", "false")
+	if cfg, err = LoadFrom(dir); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Branch != "feature/login" || cfg.Profile == nil || cfg.ProfilePre() != "feature-login" {
+		t.Fatalf("branch %q, profile %+v", cfg.Branch, cfg.Profile)
+	}
+
+	// the profile overrides the file, and is overridden by the environment
+	// and the flags
+	want := map[string]string{
+		"noFetch":  "true from profile:feature/*",
+		"noCommit": "false from env:BUMPY_NO_COMMIT",
+	}
+	for k, v := range want {
+		if got := originOf(cfg, k); got != v {
+			t.Errorf("%v = %v, want %v", k, got, v)
+		}
+	}
+	if !cfg.NoFetch || cfg.NoCommit {
+		t.Errorf("noFetch %v, noCommit %v", cfg.NoFetch, cfg.NoCommit)
+	}
+
+	if cfg, err = LoadFrom(dir, Flag{Name: "--fetch", Key: "noFetch", Value: false}); err != nil {
+		t.Fatal(err)
+	}
+	if got := originOf(cfg, "noFetch"); got != "false from flag:--fetch" {
+		t.Errorf("noFetch = %v, want false from flag:--fetch", got)
+	}
+}
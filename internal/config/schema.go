@@ -0,0 +1,349 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/token"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+)
+
+// migrations upgrade the raw configuration from the schema version matching
+// their index to the next one
+var migrations = []func(raw map[string]any) error{
+	// 0 → 1: `schemaVersion` is introduced, with no other changes
+	func(raw map[string]any) error { return nil },
+}
+
+// SchemaVersion is the current version of the configuration file schema
+var SchemaVersion = len(migrations)
+
+// decode decodes the given configuration file contents, upgrading them to the
+// current schema version and rejecting unknown keys
+func (cfg *Config) decode(bv []byte) (err error) {
+	raw, from, err := upgrade(bv, cfg.File)
+	if err != nil {
+		return
+	}
+
+	if err = cfg.decodeRaw(raw); err != nil {
+		return
+	}
+
+	cfg.fileSchemaVersion = from
+	return
+}
+
+// decodeRaw decodes the given raw configuration, as returned by upgrade
+func (cfg *Config) decodeRaw(raw map[string]any) (err error) {
+	bv, err := json.Marshal(raw)
+	if err != nil {
+		return
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(bv))
+	dec.DisallowUnknownFields()
+	if err = dec.Decode(cfg); err != nil {
+		err = explain(err, cfg.File)
+	}
+	return
+}
+
+// upgrade returns the given configuration file contents as a raw map,
+// upgraded to the current schema version, along with the schema version of
+// the contents
+func upgrade(bv []byte, name string) (raw map[string]any, from int, err error) {
+	var meta struct {
+		SchemaVersion int `json:"schemaVersion"`
+	}
+	if err = json.Unmarshal(bv, &meta); err != nil {
+		err = explain(err, name)
+		return
+	}
+
+	if meta.SchemaVersion > SchemaVersion || meta.SchemaVersion < 0 {
+		err = fmt.Errorf("%v has schema version %v, but only up to %v is supported (please upgrade bumpy)", name, meta.SchemaVersion, SchemaVersion)
+		return
+	}
+
+	if bv, err = migrate(bv, meta.SchemaVersion, name); err != nil {
+		return
+	}
+
+	// encoding/json matches keys case-insensitively
+	if err = json.Unmarshal(bv, &raw); err != nil {
+		err = explain(err, name)
+		return
+	}
+	if err = checkKeys(raw, reflect.TypeOf(Config{}), name); err != nil {
+		return
+	}
+
+	from = meta.SchemaVersion
+	return
+}
+
+// FileSchemaVersion returns the schema version of the configuration file as
+// read, before any migration
+func (cfg *Config) FileSchemaVersion() int {
+	return cfg.fileSchemaVersion
+}
+
+func migrate(bv []byte, from int, name string) ([]byte, error) {
+	if from == SchemaVersion {
+		return bv, nil
+	}
+
+	raw := map[string]any{}
+	if err := json.Unmarshal(bv, &raw); err != nil {
+		return nil, explain(err, name)
+	}
+
+	for i := from; i < SchemaVersion; i++ {
+		if err := migrations[i](raw); err != nil {
+			return nil, fmt.Errorf("Unable to migrate %v to schema version %v: %w", name, i+1, err)
+		}
+	}
+	raw["schemaVersion"] = SchemaVersion
+
+	return json.Marshal(raw)
+}
+
+// Validate checks the configured values
+func (cfg *Config) Validate() error {
+	var errs []error
+
+	if fi, err := os.Stat(cfg.Path(cfg.VersionPrefix)); err != nil || !fi.IsDir() {
+		errs = append(errs, fmt.Errorf("Invalid `versionPrefix` in %v: %v is not a directory", cfg.source("versionPrefix"), strconv.Quote(cfg.VersionPrefix)))
+	}
+
+	for _, p := range []struct {
+		key  string
+		list []string
+	}{
+		{"npmPrefixes", cfg.NPMPrefixes},
+		{"cargoPrefixes", cfg.CargoPrefixes},
+		{"mavenPrefixes", cfg.MavenPrefixes},
+		{"gradlePrefixes", cfg.GradlePrefixes},
+	} {
+		for _, dir := range p.list {
+			if fi, err := os.Stat(cfg.Path(dir)); err != nil || !fi.IsDir() {
+				errs = append(errs, fmt.Errorf("Invalid `%v` entry in %v: %v is not a directory", p.key, cfg.source(p.key), strconv.Quote(dir)))
+			}
+		}
+	}
+
+	for i, g := range cfg.Generate {
+		if g.Target == "" {
+			errs = append(errs, fmt.Errorf("Invalid `generate` entry #%v in %v: missing `target`", i+1, cfg.source("generate")))
+		}
+		if g.Template == "" {
+			errs = append(errs, fmt.Errorf("Invalid `generate` entry #%v in %v: missing `template`", i+1, cfg.source("generate")))
+		} else if err := cfg.checkTemplate(g.Template); err != nil {
+			errs = append(errs, fmt.Errorf("Invalid `generate` entry #%v in %v: %w", i+1, cfg.source("generate"), err))
+		}
+		if g.Package != "" && !token.IsIdentifier(g.Package) {
+			errs = append(errs, fmt.Errorf("Invalid `generate` entry #%v in %v: %v is not a valid package name", i+1, cfg.source("generate"), strconv.Quote(g.Package)))
+		}
+	}
+
+	if cfg.LDFlags != nil {
+		for _, v := range []struct {
+			key  string
+			name string
+		}{
+			{"ldflags.version", cfg.LDFlags.Version},
+			{"ldflags.commit", cfg.LDFlags.Commit},
+			{"ldflags.date", cfg.LDFlags.Date},
+		} {
+			if v.name != "" && v.name != LDFlagsDisabled && !validVar(v.name) {
+				errs = append(errs, fmt.Errorf("Invalid `%v` in %v: %v is not a fully-qualified variable name, e.g., `main.Version`", v.key, cfg.source(v.key), strconv.Quote(v.name)))
+			}
+		}
+	}
+
+	for i, p := range cfg.Profiles {
+		if p.Branch == "" {
+			errs = append(errs, fmt.Errorf("Invalid `profiles` entry #%v in %v: missing `branch`", i+1, cfg.source("profiles")))
+		} else if _, err := path.Match(p.Branch, ""); err != nil {
+			errs = append(errs, fmt.Errorf("Invalid `profiles` entry #%v in %v: %v is not a valid glob", i+1, cfg.source("profiles"), strconv.Quote(p.Branch)))
+		}
+		for _, inc := range p.Increments {
+			if !slices.Contains([]string{IncrementPatch, IncrementMinor, IncrementMajor}, inc) {
+				errs = append(errs, fmt.Errorf("Invalid `profiles` entry #%v in %v: unsupported increment %v", i+1, cfg.source("profiles"), strconv.Quote(inc)))
+			}
+		}
+	}
+
+	if !slices.Contains(vcs.Backends, cfg.Backend) {
+		errs = append(errs, fmt.Errorf("Invalid `vcs` in %v: unsupported backend %v", cfg.source("vcs"), strconv.Quote(cfg.Backend)))
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkTemplate verifies that the given template, either built-in or a file,
+// can be loaded
+func (cfg *Config) checkTemplate(name string) (err error) {
+	if slices.Contains(Templates, name) {
+		return
+	}
+
+	bv, err := os.ReadFile(cfg.Path(name))
+	if err != nil {
+		err = fmt.Errorf("Unable to read template `%v`: %w", name, err)
+		return
+	}
+
+	_, err = template.New(filepath.Base(name)).Parse(string(bv))
+	return
+}
+
+// validVar tells whether the given name is a fully-qualified Go variable, as
+// expected by `go build -ldflags -X`, i.e., an import path and an identifier
+// joined by a dot
+func validVar(name string) bool {
+	i := strings.LastIndex(name, ".")
+	if i <= 0 || !token.IsIdentifier(name[i+1:]) {
+		return false
+	}
+	return !strings.ContainsAny(name[:i], " \t\n=\"'")
+}
+
+// explain turns JSON decoding errors into messages naming the offending key
+func explain(err error, name string) error {
+	var typeErr *json.UnmarshalTypeError
+	var syntaxErr *json.SyntaxError
+
+	switch {
+	case errors.As(err, &typeErr):
+		return fmt.Errorf("Invalid value for `%v` in %v: expected %v, got %v", typeErr.Field, name, typeErr.Type, typeErr.Value)
+	case errors.As(err, &syntaxErr):
+		return fmt.Errorf("Invalid JSON in %v, at offset %v: %w", name, syntaxErr.Offset, err)
+	}
+
+	if s, ok := strings.CutPrefix(err.Error(), "json: unknown field "); ok {
+		key, _ := strconv.Unquote(s)
+		msg := fmt.Sprintf("Unknown key `%v` in %v", key, name)
+		if known := closestKey(key); known != "" {
+			msg += fmt.Sprintf(", did you mean `%v`?", known)
+		}
+		return errors.New(msg)
+	}
+
+	return err
+}
+
+// checkKeys verifies that every key of the given raw value matches exactly a
+// JSON key of the given type
+func checkKeys(raw any, t reflect.Type, name string) error {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch v := raw.(type) {
+	case []any:
+		if t.Kind() != reflect.Slice {
+			return nil
+		}
+		for _, e := range v {
+			if err := checkKeys(e, t.Elem(), name); err != nil {
+				return err
+			}
+		}
+	case map[string]any:
+		if t.Kind() != reflect.Struct {
+			return nil
+		}
+
+		fields := map[string]reflect.Type{}
+		for i := 0; i < t.NumField(); i++ {
+			key, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+			if key != "" && key != "-" {
+				fields[key] = t.Field(i).Type
+			}
+		}
+
+		for k, e := range v {
+			ft, ok := fields[k]
+			if !ok {
+				return explain(fmt.Errorf("json: unknown field %q", k), name)
+			}
+			if err := checkKeys(e, ft, name); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// closestKey returns the known key most similar to the given one, if any is
+// close enough
+func closestKey(key string) (closest string) {
+	best := len(key)/2 + 1
+	for _, k := range knownKeys(reflect.TypeOf(Config{})) {
+		if strings.EqualFold(k, key) {
+			return k
+		}
+		if d := distance(strings.ToLower(k), strings.ToLower(key)); d < best {
+			best, closest = d, k
+		}
+	}
+	return
+}
+
+// knownKeys returns the JSON keys of the given struct type, including those
+// of nested structs
+func knownKeys(t reflect.Type) (keys []string) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+
+		keys = append(keys, name)
+		keys = append(keys, knownKeys(f.Type)...)
+	}
+	return
+}
+
+// distance returns the Levenshtein distance between the given strings
+func distance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		cur := make([]int, len(b)+1)
+		cur[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+		}
+		prev = cur
+	}
+
+	return prev[len(b)]
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"ok.tmpl":     "{{.Version}}\n",
+		"broken.tmpl": "{{.Version\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		name string
+		edit func(cfg *Config)
+		want string
+	}{
+		{"defaults", func(cfg *Config) {}, ""},
+		{"builtin template", func(cfg *Config) {
+			cfg.Generate = []Generate{{Target: "v.go", Template: "go", Package: "version"}}
+		}, ""},
+		{"file template", func(cfg *Config) {
+			cfg.Generate = []Generate{{Target: "v.txt", Template: "ok.tmpl"}}
+		}, ""},
+		{"missing template", func(cfg *Config) {
+			cfg.Generate = []Generate{{Target: "v.txt", Template: "missing.tmpl"}}
+		}, "Unable to read template `missing.tmpl`"},
+		{"broken template", func(cfg *Config) {
+			cfg.Generate = []Generate{{Target: "v.txt", Template: "broken.tmpl"}}
+		}, "broken.tmpl"},
+		{"package", func(cfg *Config) {
+			cfg.Generate = []Generate{{Target: "v.go", Template: "go", Package: "my-pkg"}}
+		}, `"my-pkg" is not a valid package name`},
+		{"ldflags", func(cfg *Config) {
+			cfg.LDFlags = &LDFlags{Version: "github.com/a/b-c/pkg.Version", Commit: "main.Commit"}
+		}, ""},
+		{"ldflags disabled", func(cfg *Config) {
+			cfg.LDFlags = &LDFlags{Commit: LDFlagsDisabled, Date: LDFlagsDisabled}
+		}, ""},
+		{"ldflags without package", func(cfg *Config) {
+			cfg.LDFlags = &LDFlags{Version: "Version"}
+		}, "Invalid `ldflags.version`"},
+		{"ldflags invalid identifier", func(cfg *Config) {
+			cfg.LDFlags = &LDFlags{Date: "main.build-date"}
+		}, "Invalid `ldflags.date`"},
+		{"ldflags with spaces", func(cfg *Config) {
+			cfg.LDFlags = &LDFlags{Commit: "main.X=1 main.Commit"}
+		}, "Invalid `ldflags.commit`"},
+	}
+
+	for _, tt := range tests {
+		cfg := New()
+		cfg.Dir = dir
+		tt.edit(cfg)
+
+		err := cfg.Validate()
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+		case tt.want != "" && err == nil:
+			t.Errorf("%v: expected error", tt.name)
+		case tt.want != "" && !strings.Contains(err.Error(), tt.want):
+			t.Errorf("%v: error %q does not contain %q", tt.name, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,73 @@
+package conventional
+
+import (
+	"regexp"
+	"strings"
+)
+
+// Level defines the version increment required by a set of changes
+type Level int
+
+// Bump levels, in increasing order
+const (
+	None Level = iota
+	Patch
+	Minor
+	Major
+)
+
+var (
+	headerRe   = regexp.MustCompile(`^(\w+)(\([^)]*\))?(!)?:\s`)
+	breakingRe = regexp.MustCompile(`(?m)^BREAKING[ -]CHANGE:\s`)
+)
+
+// patchTypes lists the commit types requiring a patch increment
+var patchTypes = []string{"fix", "perf", "revert"}
+
+func (l Level) String() string {
+	switch l {
+	case Patch:
+		return "patch"
+	case Minor:
+		return "minor"
+	case Major:
+		return "major"
+	}
+	return "none"
+}
+
+// LevelOf returns the level required by a single commit, given its subject
+// and body, according to the Conventional Commits specification
+func LevelOf(subject, body string) Level {
+	match := headerRe.FindStringSubmatch(subject)
+	if match == nil {
+		return None
+	}
+
+	if match[3] == "!" || breakingRe.MatchString(body) {
+		return Major
+	}
+
+	typ := strings.ToLower(match[1])
+	if typ == "feat" {
+		return Minor
+	}
+
+	for _, t := range patchTypes {
+		if typ == t {
+			return Patch
+		}
+	}
+
+	return None
+}
+
+// Max returns the highest of the given levels
+func Max(levels ...Level) (l Level) {
+	for _, x := range levels {
+		if x > l {
+			l = x
+		}
+	}
+	return
+}
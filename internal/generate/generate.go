@@ -0,0 +1,173 @@
+package generate
+
+import (
+	"bytes"
+	"fmt"
+	"go/token"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+// Data is passed to the templates
+type Data struct {
+	// Version is the version string, with a "v" prefix
+	Version string
+
+	// SemVer is the version string, without a "v" prefix
+	SemVer string
+
+	// PEP440 is the version string, as prescribed by PEP 440
+	PEP440 string
+
+	Major int
+	Minor int
+	Patch int
+	Pre   string
+	Build string
+
+	// Package is the Go package name
+	Package string
+
+	// Guard is the include guard for C headers
+	Guard string
+}
+
+// builtin holds the templates listed in config.Templates
+var builtin = map[string]string{
+	"go": `// Code generated by bumpy; DO NOT EDIT.
+
+package {{.Package}}
+
+// Version is the current version
+const Version = "{{.SemVer}}"
+`,
+	"c": `/* Generated by bumpy; DO NOT EDIT. */
+
+#ifndef {{.Guard}}
+#define {{.Guard}}
+
+#define VERSION_MAJOR {{.Major}}
+#define VERSION_MINOR {{.Minor}}
+#define VERSION_PATCH {{.Patch}}
+#define VERSION_STRING "{{.SemVer}}"
+
+#endif /* {{.Guard}} */
+`,
+	"ts": `// Generated by bumpy; DO NOT EDIT.
+
+export const VERSION = '{{.SemVer}}';
+export const VERSION_MAJOR = {{.Major}};
+export const VERSION_MINOR = {{.Minor}};
+export const VERSION_PATCH = {{.Patch}};
+`,
+	"python": `# Generated by bumpy; DO NOT EDIT.
+
+__version__ = "{{.PEP440}}"
+__version_info__ = ({{.Major}}, {{.Minor}}, {{.Patch}})
+`,
+}
+
+// Write generates every given target, relative to dir, for the given
+// version, returning the list of written files, relative to dir
+func Write(dir string, list []config.Generate, v version.Version) (files []string, err error) {
+	for _, g := range list {
+		var bv []byte
+		if bv, err = Render(dir, g, v); err != nil {
+			return
+		}
+
+		target := filepath.Join(dir, g.Target)
+		if err = os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return
+		}
+
+		if err = os.WriteFile(target, bv, 0644); err != nil {
+			return
+		}
+
+		files = append(files, g.Target)
+	}
+
+	return
+}
+
+// Render returns the contents of the given target for the given version.
+// Paths are relative to dir
+func Render(dir string, g config.Generate, v version.Version) ([]byte, error) {
+	tmpl, err := load(dir, g.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err = tmpl.Execute(&buf, newData(dir, g, v)); err != nil {
+		return nil, fmt.Errorf("Error generating %v: %w", g.Target, err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func load(dir, name string) (tmpl *template.Template, err error) {
+	if text, ok := builtin[name]; ok {
+		tmpl, err = template.New(name).Parse(text)
+		return
+	}
+
+	bv, err := os.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		err = fmt.Errorf("Unable to read template `%v`: %w", name, err)
+		return
+	}
+
+	tmpl, err = template.New(filepath.Base(name)).Parse(string(bv))
+	return
+}
+
+func newData(dir string, g config.Generate, v version.Version) Data {
+	pkg := g.Package
+	if pkg == "" {
+		abs, _ := filepath.Abs(filepath.Join(dir, g.Target))
+		pkg = identifier(filepath.Base(filepath.Dir(abs)))
+		if token.IsKeyword(pkg) {
+			pkg += "_"
+		}
+	}
+
+	guard := strings.ToUpper(identifier(filepath.Base(g.Target)))
+
+	return Data{
+		Version: v.String(),
+		SemVer:  v.StringNoV(),
+		PEP440:  v.PEP440(),
+		Major:   v.Major,
+		Minor:   v.Minor,
+		Patch:   v.Patch,
+		Pre:     v.Pre,
+		Build:   v.Build,
+		Package: pkg,
+		Guard:   guard,
+	}
+}
+
+// identifier turns the given name into a valid identifier, replacing every
+// character other than ASCII letters, digits and underscores with an
+// underscore, and prefixing a leading digit with one
+func identifier(name string) string {
+	id := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+			return r
+		}
+		return '_'
+	}, name)
+
+	if id == "" || id[0] >= '0' && id[0] <= '9' {
+		id = "_" + id
+	}
+	return id
+}
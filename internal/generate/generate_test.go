@@ -0,0 +1,66 @@
+package generate
+
+import (
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+func TestNewDataIdentifiers(t *testing.T) {
+	tests := []struct {
+		target  string
+		pkg     string
+		wantPkg string
+		guard   string
+	}{
+		{"version/version.go", "", "version", "VERSION_GO"},
+		{"my-repo/version.go", "", "my_repo", "VERSION_GO"},
+		{"1app/version.go", "", "_1app", "VERSION_GO"},
+		{"type/version.go", "", "type_", "VERSION_GO"},
+		{"x/version.go", "custom", "custom", "VERSION_GO"},
+		{"include/1-version.h", "", "include", "_1_VERSION_H"},
+	}
+
+	for _, tt := range tests {
+		d := newData(".", config.Generate{Target: tt.target, Package: tt.pkg}, version.New())
+		if d.Package != tt.wantPkg {
+			t.Errorf("%v: package = %q, want %q", tt.target, d.Package, tt.wantPkg)
+		}
+		if d.Guard != tt.guard {
+			t.Errorf("%v: guard = %q, want %q", tt.target, d.Guard, tt.guard)
+		}
+	}
+}
+
+func TestBuiltinTemplates(t *testing.T) {
+	if len(builtin) != len(config.Templates) {
+		t.Errorf("builtin templates and config.Templates differ")
+	}
+	for _, name := range config.Templates {
+		if _, ok := builtin[name]; !ok {
+			t.Errorf("%v: missing builtin template", name)
+		}
+	}
+}
+
+func TestRenderGoCompiles(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join("my-repo", "version.go")
+
+	bv, err := Render(dir, config.Generate{Target: target, Template: "go"}, version.New())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err = parser.ParseFile(token.NewFileSet(), target, bv, 0); err != nil {
+		t.Errorf("generated file does not parse: %v\n%s", err, bv)
+	}
+	if !strings.Contains(string(bv), "package my_repo") {
+		t.Errorf("unexpected package in:\n%s", bv)
+	}
+}
@@ -0,0 +1,507 @@
+package updater
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+const (
+	// CargoManifest names the Cargo manifest file
+	CargoManifest = "Cargo.toml"
+
+	// CargoLock names the Cargo lock file
+	CargoLock = "Cargo.lock"
+)
+
+var (
+	tomlTableRe    = regexp.MustCompile(`^\s*\[\[?\s*([^\]]+?)\s*\]\]?\s*(#.*)?$`)
+	tomlKeyValueRe = regexp.MustCompile(`^\s*([A-Za-z0-9_\-."]+?)\s*=\s*(.*)$`)
+	tomlStringRe   = regexp.MustCompile(`"([^"]*)"`)
+	tomlVersionRe  = regexp.MustCompile(`^(\s*version\s*=\s*)"([^"]*)"`)
+	inlineVerRe    = regexp.MustCompile(`([{,]\s*version\s*=\s*)"([^"]*)"`)
+	inlineKeyRe    = regexp.MustCompile(`[{,]\s*([A-Za-z0-9_\-]+)\s*=`)
+	inlinePkgRe    = regexp.MustCompile(`[{,]\s*package\s*=\s*"([^"]*)"`)
+	reqOperatorRe  = regexp.MustCompile(`^\s*(=|\^|~|>=|<=|>|<)?\s*[0-9]`)
+)
+
+// cargoManifest holds the lines of a Cargo.toml file
+type cargoManifest struct {
+	path      string
+	lines     []string
+	name      string
+	version   string
+	wsVersion string
+	members   []string
+	exclude   []string
+	changed   bool
+}
+
+// UpdateCargo updates the Cargo manifests found at the given prefix, which
+// must contain either a single crate or a workspace root. Member versions,
+// `workspace.package.version` and intra-workspace dependency requirements are
+// rewritten, as well as the corresponding `Cargo.lock` entries
+func UpdateCargo(prefix string, v version.Version) (files []string, err error) {
+	root, err := readCargoManifest(filepath.Join(prefix, CargoManifest))
+	if err != nil {
+		return
+	}
+
+	manifests := []*cargoManifest{root}
+
+	var members []*cargoManifest
+	if members, err = root.workspaceMembers(os.DirFS(prefix), "."); err != nil {
+		return
+	}
+	for _, m := range members {
+		m.path = filepath.Join(prefix, filepath.FromSlash(m.path))
+	}
+	manifests = append(manifests, members...)
+
+	crates := map[string]bool{}
+	for _, m := range manifests {
+		if m.name != "" {
+			crates[m.name] = true
+		}
+	}
+
+	for _, m := range manifests {
+		m.update(v, crates)
+		if !m.changed {
+			continue
+		}
+
+		if err = m.save(); err != nil {
+			return
+		}
+		files = append(files, m.path)
+	}
+
+	lock := filepath.Join(prefix, CargoLock)
+	var changed bool
+	if changed, err = updateCargoLock(lock, v, crates); err != nil {
+		return
+	}
+	if changed {
+		files = append(files, lock)
+	}
+
+	return
+}
+
+// CargoVersions returns the versions found in the Cargo manifests and lock
+// file at the given prefix of fsys. Members inheriting the workspace version
+// are omitted
+func CargoVersions(fsys fs.FS, prefix string) (list []FileVersion, err error) {
+	rootPath := path.Join(prefix, CargoManifest)
+	bv, err := fs.ReadFile(fsys, rootPath)
+	if err != nil {
+		return
+	}
+
+	root := parseCargoManifest(rootPath, bv)
+	manifests := []*cargoManifest{root}
+
+	var members []*cargoManifest
+	if members, err = root.workspaceMembers(fsys, prefix); err != nil {
+		return
+	}
+	manifests = append(manifests, members...)
+
+	crates := map[string]bool{}
+	for _, m := range manifests {
+		if m.wsVersion != "" {
+			list = append(list, FileVersion{Path: m.path, Name: "workspace", Version: m.wsVersion})
+		}
+		if m.version != "" {
+			list = append(list, FileVersion{Path: m.path, Name: m.name, Version: m.version})
+		}
+		if m.name != "" {
+			crates[m.name] = true
+		}
+	}
+
+	lockPath := path.Join(prefix, CargoLock)
+	if bv, err = fs.ReadFile(fsys, lockPath); errors.Is(err, fs.ErrNotExist) {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+
+	for _, p := range parseCargoLock(strings.Split(string(bv), "\n")) {
+		if !p.hasSource && crates[p.name] {
+			list = append(list, FileVersion{Path: lockPath, Name: p.name, Version: p.version})
+		}
+	}
+
+	return
+}
+
+func readCargoManifest(path string) (m *cargoManifest, err error) {
+	bv, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	m = parseCargoManifest(path, bv)
+	return
+}
+
+func parseCargoManifest(path string, bv []byte) (m *cargoManifest) {
+	m = &cargoManifest{
+		path:  path,
+		lines: strings.Split(string(bv), "\n"),
+	}
+
+	var table string
+	var arrayKey string
+	var arrayBuf string
+	for _, l := range m.lines {
+		if arrayKey != "" {
+			arrayBuf += l
+			if strings.Contains(stripTOMLComment(l), "]") {
+				m.setWorkspaceList(arrayKey, arrayBuf)
+				arrayKey = ""
+			}
+			continue
+		}
+
+		if match := tomlTableRe.FindStringSubmatch(l); match != nil {
+			table = match[1]
+			continue
+		}
+
+		match := tomlKeyValueRe.FindStringSubmatch(l)
+		if match == nil {
+			continue
+		}
+
+		key, value := match[1], stripTOMLComment(match[2])
+		switch {
+		case table == "package" && key == "name":
+			if s := tomlStringRe.FindStringSubmatch(value); s != nil {
+				m.name = s[1]
+			}
+		case table == "package" && key == "version":
+			if s := tomlStringRe.FindStringSubmatch(value); s != nil && strings.HasPrefix(value, `"`) {
+				m.version = s[1]
+			}
+		case table == "workspace.package" && key == "version":
+			if s := tomlStringRe.FindStringSubmatch(value); s != nil {
+				m.wsVersion = s[1]
+			}
+		case table == "workspace" && (key == "members" || key == "exclude"):
+			if strings.Contains(value, "]") {
+				m.setWorkspaceList(key, value)
+			} else {
+				arrayKey = key
+				arrayBuf = value
+			}
+		}
+	}
+
+	return
+}
+
+func (m *cargoManifest) setWorkspaceList(key, value string) {
+	list := []string{}
+	for _, s := range tomlStringRe.FindAllStringSubmatch(value, -1) {
+		list = append(list, s[1])
+	}
+
+	if key == "members" {
+		m.members = list
+	} else {
+		m.exclude = list
+	}
+}
+
+// workspaceMembers returns the manifests of the workspace members found at
+// the given prefix of fsys, skipping the excluded ones and any matching
+// directory without a manifest
+func (m *cargoManifest) workspaceMembers(fsys fs.FS, prefix string) (list []*cargoManifest, err error) {
+	excluded := map[string]bool{}
+	for _, e := range m.exclude {
+		excluded[path.Join(prefix, e)] = true
+	}
+
+	seen := map[string]bool{path.Clean(prefix): true}
+	for _, pattern := range m.members {
+		var dirs []string
+		if dirs, err = fs.Glob(fsys, path.Join(prefix, pattern)); err != nil {
+			return
+		}
+
+		for _, d := range dirs {
+			if seen[d] || excluded[d] {
+				continue
+			}
+			seen[d] = true
+
+			mpath := path.Join(d, CargoManifest)
+			var bv []byte
+			if bv, err = fs.ReadFile(fsys, mpath); errors.Is(err, fs.ErrNotExist) {
+				err = nil
+				continue
+			} else if err != nil {
+				return
+			}
+			list = append(list, parseCargoManifest(mpath, bv))
+		}
+	}
+
+	return
+}
+
+func (m *cargoManifest) update(v version.Version, crates map[string]bool) {
+	var table string
+	var depTable bool
+	var depName string
+	var depStart int
+	var depPath bool
+
+	flushDep := func(end int) {
+		if depName == "" || !depPath || !crates[depName] {
+			return
+		}
+		for i := depStart; i < end; i++ {
+			m.replaceLine(i, rewriteRequirement(m.lines[i], tomlVersionRe, v))
+		}
+	}
+
+	for i, l := range m.lines {
+		if match := tomlTableRe.FindStringSubmatch(l); match != nil {
+			flushDep(i)
+			table = match[1]
+			depTable = isCargoDependencyTable(table)
+
+			depName = ""
+			depPath = false
+			if name, ok := cargoDependencySubtable(table); ok {
+				depName = name
+				depStart = i + 1
+			}
+			continue
+		}
+
+		match := tomlKeyValueRe.FindStringSubmatch(l)
+		if match == nil {
+			continue
+		}
+
+		key, value := match[1], match[2]
+		switch {
+		case (table == "package" || table == "workspace.package") && key == "version":
+			if strings.HasPrefix(strings.TrimSpace(value), `"`) {
+				m.replaceLine(i, tomlVersionRe.ReplaceAllString(l, `${1}"`+v.StringNoV()+`"`))
+			}
+		case depName != "":
+			if key == "path" {
+				depPath = true
+			} else if key == "package" {
+				if s := tomlStringRe.FindStringSubmatch(value); s != nil {
+					depName = s[1]
+				}
+			}
+		case depTable:
+			value = stripTOMLComment(value)
+			if !strings.HasPrefix(strings.TrimSpace(value), "{") {
+				continue
+			}
+
+			name := strings.Trim(key, `"`)
+			if s := inlinePkgRe.FindStringSubmatch(value); s != nil {
+				name = s[1]
+			}
+			if !crates[name] || !inlineHasKey(value, "path") {
+				continue
+			}
+
+			m.replaceLine(i, rewriteRequirement(l, inlineVerRe, v))
+		}
+	}
+
+	flushDep(len(m.lines))
+}
+
+func (m *cargoManifest) replaceLine(i int, l string) {
+	if m.lines[i] != l {
+		m.lines[i] = l
+		m.changed = true
+	}
+}
+
+func (m *cargoManifest) save() error {
+	info, err := os.Stat(m.path)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(m.path, []byte(strings.Join(m.lines, "\n")), info.Mode())
+}
+
+func updateCargoLock(path string, v version.Version, crates map[string]bool) (changed bool, err error) {
+	bv, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		err = nil
+		return
+	} else if err != nil {
+		return
+	}
+
+	lines := strings.Split(string(bv), "\n")
+	pkgs := parseCargoLock(lines)
+
+	for _, p := range pkgs {
+		if p.hasSource || p.versionIdx < 0 || !crates[p.name] {
+			continue
+		}
+
+		l := tomlVersionRe.ReplaceAllString(lines[p.versionIdx], `${1}"`+v.StringNoV()+`"`)
+		if l != lines[p.versionIdx] {
+			lines[p.versionIdx] = l
+			changed = true
+		}
+	}
+
+	if !changed {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	err = os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode())
+	return
+}
+
+// cargoLockPackage holds a package entry of a Cargo.lock file
+type cargoLockPackage struct {
+	name       string
+	version    string
+	versionIdx int
+	hasSource  bool
+}
+
+func parseCargoLock(lines []string) (pkgs []cargoLockPackage) {
+	var cur *cargoLockPackage
+
+	for i, l := range lines {
+		if match := tomlTableRe.FindStringSubmatch(l); match != nil {
+			if match[1] == "package" && strings.HasPrefix(strings.TrimSpace(l), "[[") {
+				pkgs = append(pkgs, cargoLockPackage{versionIdx: -1})
+				cur = &pkgs[len(pkgs)-1]
+			} else {
+				cur = nil
+			}
+			continue
+		}
+
+		if cur == nil {
+			continue
+		}
+
+		match := tomlKeyValueRe.FindStringSubmatch(l)
+		if match == nil {
+			continue
+		}
+
+		switch match[1] {
+		case "name":
+			if s := tomlStringRe.FindStringSubmatch(match[2]); s != nil {
+				cur.name = s[1]
+			}
+		case "version":
+			cur.versionIdx = i
+			if s := tomlStringRe.FindStringSubmatch(match[2]); s != nil {
+				cur.version = s[1]
+			}
+		case "source":
+			cur.hasSource = true
+		}
+	}
+
+	return
+}
+
+// rewriteRequirement replaces the version requirement matched by re,
+// preserving its comparison operator, if any
+func rewriteRequirement(l string, re *regexp.Regexp, v version.Version) string {
+	req := v
+	req.Build = ""
+	newVersion := req.StringNoV()
+
+	return re.ReplaceAllStringFunc(l, func(s string) string {
+		match := re.FindStringSubmatch(s)
+		op := ""
+		if !strings.Contains(match[2], ",") {
+			if m := reqOperatorRe.FindStringSubmatch(match[2]); m != nil {
+				op = m[1]
+			}
+		}
+		return match[1] + `"` + op + newVersion + `"`
+	})
+}
+
+func isCargoDependencyTable(table string) bool {
+	switch table {
+	case "dependencies", "dev-dependencies", "build-dependencies", "workspace.dependencies":
+		return true
+	}
+
+	if strings.HasPrefix(table, "target.") {
+		return strings.HasSuffix(table, ".dependencies") ||
+			strings.HasSuffix(table, ".dev-dependencies") ||
+			strings.HasSuffix(table, ".build-dependencies")
+	}
+
+	return false
+}
+
+func cargoDependencySubtable(table string) (name string, ok bool) {
+	i := strings.LastIndex(table, ".")
+	if i < 0 {
+		return
+	}
+
+	if !isCargoDependencyTable(table[:i]) {
+		return
+	}
+
+	name = strings.Trim(table[i+1:], `"`)
+	ok = true
+	return
+}
+
+func inlineHasKey(value, key string) bool {
+	for _, match := range inlineKeyRe.FindAllStringSubmatch(value, -1) {
+		if match[1] == key {
+			return true
+		}
+	}
+	return false
+}
+
+func stripTOMLComment(s string) string {
+	inString := false
+	for i, c := range s {
+		switch c {
+		case '"':
+			inString = !inString
+		case '#':
+			if !inString {
+				return strings.TrimSpace(s[:i])
+			}
+		}
+	}
+	return strings.TrimSpace(s)
+}
@@ -0,0 +1,297 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+// writeFiles writes the given files, relative to dir
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+
+	for name, content := range files {
+		p := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// checkFiles compares the given files, relative to dir, to their expected
+// content
+func checkFiles(t *testing.T, name, dir string, want map[string]string) {
+	t.Helper()
+
+	for f, content := range want {
+		bv, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(f)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(bv) != content {
+			t.Errorf("%v: %v =\n%s\nwant\n%s", name, f, bv, content)
+		}
+	}
+}
+
+// relPaths returns the given paths relative to dir, with forward slashes
+func relPaths(t *testing.T, dir string, files []string) string {
+	t.Helper()
+
+	list := make([]string, len(files))
+	for i, f := range files {
+		rel, err := filepath.Rel(dir, f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list[i] = filepath.ToSlash(rel)
+	}
+	return strings.Join(list, " ")
+}
+
+func TestUpdateCargo(t *testing.T) {
+	tests := []struct {
+		name     string
+		version  string
+		files    map[string]string
+		versions []string
+		changed  string
+		want     map[string]string
+	}{
+		{
+			name:    "single crate",
+			version: "1.2.0",
+			files: map[string]string{
+				"Cargo.toml": `[package]
+name = "app"
+version = "0.1.0" # bumped
+edition = "2021"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+`,
+				"Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+
+[[package]]
+name = "serde"
+version = "1.0.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			},
+			versions: []string{
+				"Cargo.toml app 0.1.0",
+				"Cargo.lock app 0.1.0",
+			},
+			changed: "Cargo.toml Cargo.lock",
+			want: map[string]string{
+				"Cargo.toml": `[package]
+name = "app"
+version = "1.2.0" # bumped
+edition = "2021"
+
+[dependencies]
+serde = { version = "1.0", features = ["derive"] }
+`,
+				"Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "1.2.0"
+
+[[package]]
+name = "serde"
+version = "1.0.0"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+`,
+			},
+		},
+		{
+			name:    "workspace",
+			version: "1.2.0-rc.1+build.5",
+			files: map[string]string{
+				"Cargo.toml": `[workspace]
+members = [
+    "crates/*", # every crate
+]
+exclude = ["crates/vendored"]
+
+[workspace.package]
+version = "0.1.0"
+
+[workspace.dependencies]
+util = { path = "crates/util", version = "0.1.0" }
+`,
+				"crates/app/Cargo.toml": `[package]
+name = "app"
+version.workspace = true
+
+[dependencies]
+util = { workspace = true }
+core-lib = { path = "../core", package = "core", version = "^0.1" }
+old-util = { package = "util", version = "0.0.9" }
+
+[dev-dependencies.util]
+path = "../util"
+version = "=0.1.0"
+`,
+				"crates/core/Cargo.toml": `[package]
+name = "core"
+version = "0.1.0"
+`,
+				"crates/util/Cargo.toml": `[package]
+name = "util"
+version = "0.1.0"
+`,
+				"crates/vendored/Cargo.toml": `[package]
+name = "vendored"
+version = "0.1.0"
+`,
+				"crates/docs/README.md": "Not a crate\n",
+				"Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "0.1.0"
+
+[[package]]
+name = "util"
+version = "0.1.0"
+
+[[package]]
+name = "util"
+version = "0.0.9"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "vendored"
+version = "0.1.0"
+`,
+			},
+			versions: []string{
+				"Cargo.toml workspace 0.1.0",
+				"crates/core/Cargo.toml core 0.1.0",
+				"crates/util/Cargo.toml util 0.1.0",
+				"Cargo.lock app 0.1.0",
+				"Cargo.lock util 0.1.0",
+			},
+			changed: "Cargo.toml crates/app/Cargo.toml crates/core/Cargo.toml crates/util/Cargo.toml Cargo.lock",
+			want: map[string]string{
+				"Cargo.toml": `[workspace]
+members = [
+    "crates/*", # every crate
+]
+exclude = ["crates/vendored"]
+
+[workspace.package]
+version = "1.2.0-rc.1+build.5"
+
+[workspace.dependencies]
+util = { path = "crates/util", version = "1.2.0-rc.1" }
+`,
+				"crates/app/Cargo.toml": `[package]
+name = "app"
+version.workspace = true
+
+[dependencies]
+util = { workspace = true }
+core-lib = { path = "../core", package = "core", version = "^1.2.0-rc.1" }
+old-util = { package = "util", version = "0.0.9" }
+
+[dev-dependencies.util]
+path = "../util"
+version = "=1.2.0-rc.1"
+`,
+				"crates/core/Cargo.toml": `[package]
+name = "core"
+version = "1.2.0-rc.1+build.5"
+`,
+				"crates/vendored/Cargo.toml": `[package]
+name = "vendored"
+version = "0.1.0"
+`,
+				"Cargo.lock": `version = 3
+
+[[package]]
+name = "app"
+version = "1.2.0-rc.1+build.5"
+
+[[package]]
+name = "util"
+version = "1.2.0-rc.1+build.5"
+
+[[package]]
+name = "util"
+version = "0.0.9"
+source = "registry+https://github.com/rust-lang/crates.io-index"
+
+[[package]]
+name = "vendored"
+version = "0.1.0"
+`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, tt.files)
+
+		list, err := CargoVersions(os.DirFS(dir), ".")
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		var got []string
+		for _, fv := range list {
+			got = append(got, fmt.Sprintf("%v %v %v", fv.Path, fv.Name, fv.Version))
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.versions, "\n") {
+			t.Errorf("%v: versions =\n%v\nwant\n%v", tt.name, strings.Join(got, "\n"), strings.Join(tt.versions, "\n"))
+		}
+
+		var v version.Version
+		if err = v.Parse(tt.version); err != nil {
+			t.Fatal(err)
+		}
+
+		files, err := UpdateCargo(dir, v)
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if got := relPaths(t, dir, files); got != tt.changed {
+			t.Errorf("%v: changed %q, want %q", tt.name, got, tt.changed)
+		}
+		checkFiles(t, tt.name, dir, tt.want)
+	}
+}
+
+func TestCargoVersionsPrefix(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"rust/Cargo.toml":         "[workspace]\nmembers = [\"lib\"]\n",
+		"rust/lib/Cargo.toml":     "[package]\nname = \"lib\"\nversion = \"0.3.0\"\n",
+		"rust/Cargo.lock":         "[[package]]\nname = \"lib\"\nversion = \"0.3.0\"\n",
+		"other/Cargo.toml":        "[package]\nname = \"other\"\nversion = \"9.9.9\"\n",
+		"rust/ignored/Cargo.toml": "[package]\nname = \"ignored\"\nversion = \"9.9.9\"\n",
+	})
+
+	list, err := CargoVersions(os.DirFS(dir), "rust")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := fmt.Sprint(list)
+	if want := "[{rust/lib/Cargo.toml lib 0.3.0} {rust/Cargo.lock lib 0.3.0}]"; got != want {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+}
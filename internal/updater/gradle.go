@@ -0,0 +1,164 @@
+package updater
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+const (
+	// GradleProperties names the Gradle properties file
+	GradleProperties = "gradle.properties"
+)
+
+// GradleSettings lists the names of the Gradle settings file
+var GradleSettings = []string{"settings.gradle", "settings.gradle.kts"}
+
+var (
+	gradleVersionRe = regexp.MustCompile(`^(\s*version\s*[=:]\s*)(\S.*?)(\s*)$`)
+	gradleIncludeRe = regexp.MustCompile(`^\s*include\b`)
+	gradleStringRe  = regexp.MustCompile(`["']([^"']+)["']`)
+)
+
+// UpdateGradle updates the `version` property of the `gradle.properties` file
+// found at the given prefix, as well as the ones of the subprojects included
+// by its settings file
+func UpdateGradle(prefix string, v version.Version) (files []string, err error) {
+	dirs, err := gradleProjects(os.DirFS(prefix), ".")
+	if err != nil {
+		return
+	}
+
+	newVersion := MavenVersion(v)
+	for _, d := range dirs {
+		p := filepath.Join(prefix, filepath.FromSlash(d), GradleProperties)
+
+		var changed bool
+		if changed, err = updateGradleProperties(p, newVersion); errors.Is(err, fs.ErrNotExist) {
+			err = nil
+			continue
+		} else if err != nil {
+			return
+		}
+		if changed {
+			files = append(files, p)
+		}
+	}
+
+	return
+}
+
+// GradleVersions returns the versions found in the `gradle.properties` files
+// at the given prefix of fsys, including the ones of the subprojects included
+// by its settings file
+func GradleVersions(fsys fs.FS, prefix string) (list []FileVersion, err error) {
+	dirs, err := gradleProjects(fsys, prefix)
+	if err != nil {
+		return
+	}
+
+	for _, d := range dirs {
+		p := path.Join(d, GradleProperties)
+
+		var bv []byte
+		if bv, err = fs.ReadFile(fsys, p); errors.Is(err, fs.ErrNotExist) {
+			err = nil
+			continue
+		} else if err != nil {
+			return
+		}
+
+		for _, l := range strings.Split(string(bv), "\n") {
+			if match := gradleVersionRe.FindStringSubmatch(l); match != nil {
+				list = append(list, FileVersion{Path: p, Version: match[2]})
+			}
+		}
+	}
+
+	return
+}
+
+// gradleProjects returns the directory of the root project at the given
+// prefix of fsys, followed by the ones of the subprojects declared through
+// `include` in its settings file, e.g., `include ':app', ':libs:core'` for
+// `app` and `libs/core`. Custom project directories are not followed
+func gradleProjects(fsys fs.FS, prefix string) (dirs []string, err error) {
+	prefix = path.Clean(prefix)
+	dirs = append(dirs, prefix)
+
+	var bv []byte
+	for _, name := range GradleSettings {
+		if bv, err = fs.ReadFile(fsys, path.Join(prefix, name)); err == nil {
+			break
+		} else if !errors.Is(err, fs.ErrNotExist) {
+			return
+		}
+	}
+	if err != nil {
+		err = nil
+		return
+	}
+
+	seen := map[string]bool{prefix: true}
+	var include bool
+	var depth int
+	for _, l := range strings.Split(string(bv), "\n") {
+		l, _, _ = strings.Cut(l, "//")
+		if gradleIncludeRe.MatchString(l) {
+			include, depth = true, 0
+		} else if !include {
+			continue
+		}
+
+		for _, match := range gradleStringRe.FindAllStringSubmatch(l, -1) {
+			d := path.Join(prefix, strings.ReplaceAll(strings.Trim(match[1], ":"), ":", "/"))
+			if !seen[d] && fs.ValidPath(d) {
+				seen[d] = true
+				dirs = append(dirs, d)
+			}
+		}
+
+		// an include continues on the next line within its parentheses, or
+		// after a trailing comma
+		depth += strings.Count(l, "(") - strings.Count(l, ")")
+		include = depth > 0 || strings.HasSuffix(strings.TrimSpace(l), ",")
+	}
+
+	return
+}
+
+func updateGradleProperties(path, newVersion string) (changed bool, err error) {
+	bv, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	lines := strings.Split(string(bv), "\n")
+	for i, l := range lines {
+		match := gradleVersionRe.FindStringSubmatch(l)
+		if match == nil || match[2] == newVersion {
+			continue
+		}
+
+		lines[i] = match[1] + newVersion + match[3]
+		changed = true
+	}
+
+	if !changed {
+		return
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return
+	}
+
+	err = os.WriteFile(path, []byte(strings.Join(lines, "\n")), info.Mode())
+	return
+}
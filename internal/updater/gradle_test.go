@@ -0,0 +1,84 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+func TestGradleProjects(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings map[string]string
+		want     string
+	}{
+		{"no settings", nil, "[.]"},
+		{
+			"groovy",
+			map[string]string{"settings.gradle": "rootProject.name = 'demo'\ninclude 'app', ':libs:core'\n// include 'old'\nincludeBuild 'tools'\n"},
+			"[. app libs/core]",
+		},
+		{
+			"kotlin",
+			map[string]string{"settings.gradle.kts": "include(\n    \"app\",\n    \"libs:core\"\n)\ninclude(\"extra\")\n"},
+			"[. app libs/core extra]",
+		},
+		{
+			"continued",
+			map[string]string{"settings.gradle": "include 'app',\n        'lib'\nrootProject.name = 'x'\n"},
+			"[. app lib]",
+		},
+	}
+
+	for _, tt := range tests {
+		dir := t.TempDir()
+		writeFiles(t, dir, tt.settings)
+
+		dirs, err := gradleProjects(os.DirFS(dir), ".")
+		if err != nil {
+			t.Fatalf("%v: %v", tt.name, err)
+		}
+		if got := fmt.Sprint(dirs); got != tt.want {
+			t.Errorf("%v: projects = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateGradle(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"settings.gradle":             "include ':app', ':libs:core', ':missing'\n",
+		"gradle.properties":           "group=com.example\nversion=0.1.0\n",
+		"app/gradle.properties":       "version = 0.1.0\n",
+		"libs/core/gradle.properties": "org.gradle.jvmargs=-Xmx1g\nversion: 0.1.0\n",
+		"vendor/gradle.properties":    "version=9.9.9\n",
+	})
+
+	list, err := GradleVersions(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := fmt.Sprint(list)
+	want := "[{gradle.properties  0.1.0} {app/gradle.properties  0.1.0} {libs/core/gradle.properties  0.1.0}]"
+	if got != want {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+
+	files, err := UpdateGradle(dir, version.Version{Major: 2, Pre: "dev.4"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := relPaths(t, dir, files); got != "gradle.properties app/gradle.properties libs/core/gradle.properties" {
+		t.Errorf("changed %q", got)
+	}
+
+	checkFiles(t, "gradle", dir, map[string]string{
+		"gradle.properties":           "group=com.example\nversion=2.0.0-SNAPSHOT\n",
+		"app/gradle.properties":       "version = 2.0.0-SNAPSHOT\n",
+		"libs/core/gradle.properties": "org.gradle.jvmargs=-Xmx1g\nversion: 2.0.0-SNAPSHOT\n",
+		"vendor/gradle.properties":    "version=9.9.9\n",
+	})
+}
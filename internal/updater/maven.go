@@ -0,0 +1,329 @@
+package updater
+
+import (
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+const (
+	// MavenPOM names the Maven project file
+	MavenPOM = "pom.xml"
+
+	// MavenSnapshot is the Maven qualifier for development versions
+	MavenSnapshot = "SNAPSHOT"
+)
+
+// snapshotIdentifiers lists the prerelease identifiers that map to a
+// Maven/Gradle `-SNAPSHOT` version
+var snapshotIdentifiers = []string{"snapshot", "dev"}
+
+// pomSpan locates the text of an element in a POM file
+type pomSpan struct {
+	start int
+	end   int
+	value string
+}
+
+// pomRef holds the coordinates of a project, parent or dependency
+type pomRef struct {
+	groupID    string
+	artifactID string
+	version    *pomSpan
+}
+
+func (r pomRef) key() string {
+	return r.groupID + ":" + r.artifactID
+}
+
+// pomFile holds the relevant parts of a POM file
+type pomFile struct {
+	path    string
+	data    []byte
+	project pomRef
+	parent  pomRef
+	modules []string
+	deps    []pomRef
+}
+
+// MavenVersion returns the Maven/Gradle representation of the given version.
+// Prereleases whose first identifier is `SNAPSHOT` or `dev` map to the
+// `-SNAPSHOT` qualifier, other prereleases are kept as qualifiers, and build
+// metadata is dropped
+func MavenVersion(v version.Version) string {
+	out := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+	if v.Pre == "" {
+		return out
+	}
+
+	first, _, _ := strings.Cut(v.Pre, ".")
+	for _, id := range snapshotIdentifiers {
+		if strings.EqualFold(first, id) {
+			return out + "-" + MavenSnapshot
+		}
+	}
+
+	return out + "-" + v.Pre
+}
+
+// UpdateMaven updates the `pom.xml` found at the given prefix, as well as the
+// ones of its modules, recursively. Project versions, parent references and
+// dependency versions pointing to any of the reactor's modules are rewritten
+func UpdateMaven(prefix string, v version.Version) (files []string, err error) {
+	poms, err := readMavenReactor(filepath.Join(prefix, MavenPOM), map[string]bool{})
+	if err != nil {
+		return
+	}
+
+	reactor := map[string]bool{}
+	for _, p := range poms {
+		reactor[p.project.key()] = true
+	}
+
+	newVersion := MavenVersion(v)
+	for _, p := range poms {
+		spans := []*pomSpan{p.project.version}
+		if reactor[p.parent.key()] {
+			spans = append(spans, p.parent.version)
+		}
+		for _, d := range p.deps {
+			if reactor[d.key()] {
+				spans = append(spans, d.version)
+			}
+		}
+
+		var changed bool
+		if changed, err = p.rewrite(spans, newVersion); err != nil {
+			return
+		}
+		if changed {
+			files = append(files, p.path)
+		}
+	}
+
+	return
+}
+
+func readMavenReactor(path string, seen map[string]bool) (list []*pomFile, err error) {
+	path = filepath.Clean(path)
+	if seen[path] {
+		return
+	}
+	seen[path] = true
+
+	p, err := readPOM(path)
+	if err != nil {
+		return
+	}
+	list = append(list, p)
+
+	for _, m := range p.modules {
+		mpath := filepath.Join(filepath.Dir(path), m)
+		if filepath.Ext(mpath) != ".xml" {
+			mpath = filepath.Join(mpath, MavenPOM)
+		}
+
+		var children []*pomFile
+		if children, err = readMavenReactor(mpath, seen); err != nil {
+			return
+		}
+		list = append(list, children...)
+	}
+
+	return
+}
+
+// MavenVersions returns the versions found in the `pom.xml` at the given
+// prefix of fsys, as well as the ones of its modules. Modules inheriting the
+// version from their parent report the parent's version
+func MavenVersions(fsys fs.FS, prefix string) (list []FileVersion, err error) {
+	seen := map[string]bool{}
+
+	var walk func(p string) error
+	walk = func(p string) error {
+		p = path.Clean(p)
+		if seen[p] {
+			return nil
+		}
+		seen[p] = true
+
+		data, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+
+		pom, err := parsePOM(p, data)
+		if err != nil {
+			return err
+		}
+
+		ver := pom.parent.version
+		if pom.project.version != nil {
+			ver = pom.project.version
+		}
+		if ver != nil {
+			list = append(list, FileVersion{Path: p, Name: pom.project.key(), Version: ver.value})
+		}
+
+		for _, m := range pom.modules {
+			mpath := path.Join(path.Dir(p), m)
+			if path.Ext(mpath) != ".xml" {
+				mpath = path.Join(mpath, MavenPOM)
+			}
+			if err = walk(mpath); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	err = walk(path.Join(prefix, MavenPOM))
+	return
+}
+
+func readPOM(path string) (p *pomFile, err error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return
+	}
+
+	p, err = parsePOM(path, data)
+	return
+}
+
+func parsePOM(path string, data []byte) (p *pomFile, err error) {
+	p = &pomFile{path: path, data: data}
+
+	d := xml.NewDecoder(bytes.NewReader(data))
+	d.Strict = false
+
+	var stack []string
+	var starts []int
+	var text string
+	var dep *pomRef
+
+	for {
+		pos := int(d.InputOffset())
+
+		var tok xml.Token
+		if tok, err = d.Token(); errors.Is(err, io.EOF) {
+			err = nil
+			break
+		} else if err != nil {
+			return
+		}
+
+		switch t := tok.(type) {
+		case xml.StartElement:
+			stack = append(stack, t.Name.Local)
+			starts = append(starts, int(d.InputOffset()))
+			text = ""
+
+			if isPOMDependency(strings.Join(stack, "/")) {
+				dep = &pomRef{}
+			}
+		case xml.CharData:
+			text += string(t)
+		case xml.EndElement:
+			if len(stack) == 0 {
+				continue
+			}
+
+			path := strings.Join(stack, "/")
+			value := strings.TrimSpace(text)
+			span := &pomSpan{start: starts[len(starts)-1], end: pos, value: value}
+
+			switch {
+			case path == "project/groupId":
+				p.project.groupID = value
+			case path == "project/artifactId":
+				p.project.artifactID = value
+			case path == "project/version":
+				p.project.version = span
+			case path == "project/parent/groupId":
+				p.parent.groupID = value
+			case path == "project/parent/artifactId":
+				p.parent.artifactID = value
+			case path == "project/parent/version":
+				p.parent.version = span
+			case path == "project/modules/module":
+				p.modules = append(p.modules, value)
+			case dep != nil && strings.HasSuffix(path, "/dependency/groupId"):
+				dep.groupID = value
+			case dep != nil && strings.HasSuffix(path, "/dependency/artifactId"):
+				dep.artifactID = value
+			case dep != nil && strings.HasSuffix(path, "/dependency/version"):
+				dep.version = span
+			case dep != nil && isPOMDependency(path):
+				p.deps = append(p.deps, *dep)
+				dep = nil
+			}
+
+			stack = stack[:len(stack)-1]
+			starts = starts[:len(starts)-1]
+			text = ""
+		}
+	}
+
+	if p.project.groupID == "" {
+		p.project.groupID = p.parent.groupID
+	}
+
+	return
+}
+
+func (p *pomFile) rewrite(spans []*pomSpan, newVersion string) (changed bool, err error) {
+	valid := []*pomSpan{}
+	for _, s := range spans {
+		if s == nil || s.value == "" || strings.Contains(s.value, "${") || s.value == newVersion {
+			continue
+		}
+		valid = append(valid, s)
+	}
+
+	if len(valid) == 0 {
+		return
+	}
+
+	sort.Slice(valid, func(i, j int) bool { return valid[i].start > valid[j].start })
+
+	data := p.data
+	for _, s := range valid {
+		raw := string(data[s.start:s.end])
+		replaced := strings.Replace(raw, s.value, newVersion, 1)
+
+		out := make([]byte, 0, len(data)+len(replaced)-len(raw))
+		out = append(out, data[:s.start]...)
+		out = append(out, replaced...)
+		out = append(out, data[s.end:]...)
+		data = out
+	}
+
+	info, err := os.Stat(p.path)
+	if err != nil {
+		return
+	}
+
+	if err = os.WriteFile(p.path, data, info.Mode()); err != nil {
+		return
+	}
+
+	p.data = data
+	changed = true
+	return
+}
+
+func isPOMDependency(path string) bool {
+	return path == "project/dependencies/dependency" ||
+		path == "project/dependencyManagement/dependencies/dependency"
+}
@@ -0,0 +1,173 @@
+package updater
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+func TestMavenVersion(t *testing.T) {
+	tests := []struct {
+		v    version.Version
+		want string
+	}{
+		{version.Version{Major: 1, Minor: 2}, "1.2.0"},
+		{version.Version{Major: 1, Minor: 2, Build: "build.5"}, "1.2.0"},
+		{version.Version{Major: 1, Minor: 2, Pre: "SNAPSHOT"}, "1.2.0-SNAPSHOT"},
+		{version.Version{Major: 1, Minor: 2, Pre: "snapshot.3"}, "1.2.0-SNAPSHOT"},
+		{version.Version{Major: 1, Minor: 2, Pre: "dev.1", Build: "5"}, "1.2.0-SNAPSHOT"},
+		{version.Version{Major: 1, Minor: 2, Pre: "rc.1"}, "1.2.0-rc.1"},
+		{version.Version{Major: 1, Minor: 2, Pre: "devel"}, "1.2.0-devel"},
+	}
+
+	for _, tt := range tests {
+		if got := MavenVersion(tt.v); got != tt.want {
+			t.Errorf("%+v: MavenVersion() = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestUpdateMaven(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"pom.xml": `<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.2.0</version>
+  </parent>
+  <groupId>com.example</groupId>
+  <artifactId>root</artifactId>
+  <version>0.1.0</version>
+  <modules>
+    <module>core</module>
+    <module>app/pom.xml</module>
+  </modules>
+</project>
+`,
+		"core/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>root</artifactId>
+    <version>0.1.0</version>
+  </parent>
+  <artifactId>core</artifactId>
+</project>
+`,
+		"app/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>root</artifactId>
+    <version>0.1.0</version>
+  </parent>
+  <artifactId>app</artifactId>
+  <version>0.1.0</version>
+  <dependencies>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>core</artifactId>
+      <version>0.1.0</version>
+    </dependency>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>root</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>4.13.2</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		"other/pom.xml": `<project>
+  <groupId>com.example</groupId>
+  <artifactId>other</artifactId>
+  <version>0.1.0</version>
+</project>
+`,
+	})
+
+	list, err := MavenVersions(os.DirFS(dir), ".")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// core inherits the version of its parent
+	got := fmt.Sprint(list)
+	want := "[{pom.xml com.example:root 0.1.0} {core/pom.xml com.example:core 0.1.0} {app/pom.xml com.example:app 0.1.0}]"
+	if got != want {
+		t.Errorf("versions = %v, want %v", got, want)
+	}
+
+	files, err := UpdateMaven(dir, version.Version{Major: 1, Minor: 0, Pre: "SNAPSHOT"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := relPaths(t, dir, files); got != "pom.xml core/pom.xml app/pom.xml" {
+		t.Errorf("changed %q", got)
+	}
+
+	checkFiles(t, "maven", dir, map[string]string{
+		"pom.xml": `<project>
+  <parent>
+    <groupId>org.springframework.boot</groupId>
+    <artifactId>spring-boot-starter-parent</artifactId>
+    <version>3.2.0</version>
+  </parent>
+  <groupId>com.example</groupId>
+  <artifactId>root</artifactId>
+  <version>1.0.0-SNAPSHOT</version>
+  <modules>
+    <module>core</module>
+    <module>app/pom.xml</module>
+  </modules>
+</project>
+`,
+		"core/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>root</artifactId>
+    <version>1.0.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>core</artifactId>
+</project>
+`,
+		"app/pom.xml": `<project>
+  <parent>
+    <groupId>com.example</groupId>
+    <artifactId>root</artifactId>
+    <version>1.0.0-SNAPSHOT</version>
+  </parent>
+  <artifactId>app</artifactId>
+  <version>1.0.0-SNAPSHOT</version>
+  <dependencies>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>core</artifactId>
+      <version>1.0.0-SNAPSHOT</version>
+    </dependency>
+    <dependency>
+      <groupId>com.example</groupId>
+      <artifactId>root</artifactId>
+      <version>${project.version}</version>
+    </dependency>
+    <dependency>
+      <groupId>junit</groupId>
+      <artifactId>junit</artifactId>
+      <version>4.13.2</version>
+    </dependency>
+  </dependencies>
+</project>
+`,
+		"other/pom.xml": `<project>
+  <groupId>com.example</groupId>
+  <artifactId>other</artifactId>
+  <version>0.1.0</version>
+</project>
+`,
+	})
+}
@@ -0,0 +1,52 @@
+// Package updater keeps the version of third-party project files in sync
+// with version.json.
+package updater
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"path"
+)
+
+const (
+	// NPMPackage names the npm package file
+	NPMPackage = "package.json"
+
+	// NPMPackageLock names the npm lock file
+	NPMPackageLock = "package-lock.json"
+)
+
+// FileVersion holds the version found in a project file
+type FileVersion struct {
+	Path    string `json:"path"`
+	Name    string `json:"name,omitempty"`
+	Version string `json:"version"`
+}
+
+// NPMVersions returns the versions found in the npm package and lock files
+// at the given prefix of fsys
+func NPMVersions(fsys fs.FS, prefix string) (list []FileVersion, err error) {
+	for _, name := range []string{NPMPackage, NPMPackageLock} {
+		p := path.Join(prefix, name)
+
+		var bv []byte
+		if bv, err = fs.ReadFile(fsys, p); errors.Is(err, fs.ErrNotExist) && name == NPMPackageLock {
+			err = nil
+			continue
+		} else if err != nil {
+			return
+		}
+
+		var pkg struct {
+			Version string `json:"version"`
+		}
+		if err = json.Unmarshal(bv, &pkg); err != nil {
+			return
+		}
+
+		list = append(list, FileVersion{Path: p, Version: pkg.Version})
+	}
+
+	return
+}
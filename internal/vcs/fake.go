@@ -0,0 +1,347 @@
+package vcs
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Fake implements VCS in memory, with a linear history, for testing
+// purposes. Committed files are read from disk, relative to Dir
+type Fake struct {
+	// Dir is the top-level directory
+	Dir string
+
+	// Commits lists the history, oldest first
+	Commits []FakeCommit
+
+	// TagList lists the tags, in creation order
+	TagList []Tag
+
+	// Staged, Unstaged and Untracked are returned by Status
+	Staged    []string
+	Unstaged  []string
+	Untracked []string
+
+	// CurrentBranch is returned by Branch. Empty stands for detached HEAD
+	CurrentBranch string
+
+	// Fetches counts the times LatestTag fetched
+	Fetches int
+
+	// Err, if set, is returned by every method
+	Err error
+
+	// Now returns the time of new commits and tags. Defaults to time.Now
+	Now func() time.Time
+}
+
+// FakeCommit is a commit of the Fake history
+type FakeCommit struct {
+	Commit
+
+	// Files holds the snapshot of the tree at this commit
+	Files map[string][]byte
+}
+
+var _ VCS = (*Fake)(nil)
+
+// NewFake returns an empty Fake rooted at dir
+func NewFake(dir string) *Fake {
+	return &Fake{Dir: dir}
+}
+
+// TopLevel implements the VCS interface
+func (f *Fake) TopLevel() string {
+	return f.Dir
+}
+
+// LatestTag implements the VCS interface
+func (f *Fake) LatestTag(noFetch bool) (tag string, err error) {
+	if f.Err != nil {
+		return "", f.Err
+	}
+
+	if !noFetch {
+		f.Fetches++
+	}
+
+	if len(f.TagList) == 0 {
+		err = errors.New("fatal: No names found, cannot describe anything.")
+		return
+	}
+
+	latest := -1
+	for i, t := range f.TagList {
+		if latest < 0 || f.index(t.Commit) >= f.index(f.TagList[latest].Commit) {
+			latest = i
+		}
+	}
+
+	tag = f.TagList[latest].Name
+	return
+}
+
+// Tags implements the VCS interface
+func (f *Fake) Tags() ([]Tag, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	return append([]Tag{}, f.TagList...), nil
+}
+
+// CommitFiles implements the VCS interface
+func (f *Fake) CommitFiles(files []string, msg string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+
+	snapshot := map[string][]byte{}
+	if n := len(f.Commits); n > 0 {
+		for k, v := range f.Commits[n-1].Files {
+			snapshot[k] = v
+		}
+	}
+
+	for _, file := range files {
+		abs, err := filepath.Abs(file)
+		if err != nil {
+			return err
+		}
+
+		rel, err := filepath.Rel(f.Dir, abs)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+
+		bv, err := os.ReadFile(abs)
+		if errors.Is(err, fs.ErrNotExist) {
+			delete(snapshot, rel)
+			continue
+		} else if err != nil {
+			return err
+		}
+		snapshot[rel] = bv
+	}
+
+	subject, body, _ := strings.Cut(msg, "\n")
+	f.Commits = append(f.Commits, FakeCommit{
+		Commit: Commit{
+			Hash:    fmt.Sprintf("%040x", len(f.Commits)+1),
+			Time:    f.now(),
+			Author:  "Fake",
+			Subject: subject,
+			Body:    strings.TrimSpace(body),
+		},
+		Files: snapshot,
+	})
+	return nil
+}
+
+// NewTag implements the VCS interface
+func (f *Fake) NewTag(name, msg string) error {
+	if f.Err != nil {
+		return f.Err
+	}
+
+	if len(f.Commits) == 0 {
+		return errors.New("fatal: Failed to resolve 'HEAD' as a valid ref.")
+	}
+
+	for _, t := range f.TagList {
+		if t.Name == name {
+			return fmt.Errorf("fatal: tag '%v' already exists", name)
+		}
+	}
+
+	subject, _, _ := strings.Cut(msg, "\n")
+	f.TagList = append(f.TagList, Tag{
+		Name:      name,
+		Commit:    f.Commits[len(f.Commits)-1].Hash,
+		Annotated: true,
+		Date:      f.now(),
+		Tagger:    "Fake",
+		Subject:   subject,
+		Message:   msg,
+	})
+	return nil
+}
+
+// Log implements the VCS interface
+func (f *Fake) Log(from, to string) (list []Commit, err error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	lo := -1
+	if from != "" {
+		if lo, err = f.resolve(from); err != nil {
+			return
+		}
+	}
+
+	hi, err := f.resolve(to)
+	if err != nil {
+		return
+	}
+
+	for i := hi; i > lo; i-- {
+		list = append(list, f.Commits[i].Commit)
+	}
+	return
+}
+
+// Head implements the VCS interface
+func (f *Fake) Head() (c Commit, err error) {
+	i, err := f.resolve("HEAD")
+	if err != nil {
+		return
+	}
+	c = f.Commits[i].Commit
+	return
+}
+
+// Branch implements the VCS interface
+func (f *Fake) Branch() (string, error) {
+	return f.CurrentBranch, f.Err
+}
+
+// Status implements the VCS interface
+func (f *Fake) Status() (staged, unstaged, untracked []string, err error) {
+	return f.Staged, f.Unstaged, f.Untracked, f.Err
+}
+
+// Show implements the VCS interface
+func (f *Fake) Show(rev, path string) ([]byte, error) {
+	i, err := f.resolve(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	bv, ok := f.Commits[i].Files[path]
+	if !ok {
+		return nil, fmt.Errorf("fatal: path '%v' does not exist in '%v'", path, rev)
+	}
+	return bv, nil
+}
+
+// TreeFS implements the VCS interface
+func (f *Fake) TreeFS(rev string) (fs.FS, error) {
+	i, err := f.resolve(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshot := f.Commits[i].Files
+	files := map[string]int64{}
+	for p, bv := range snapshot {
+		files[p] = int64(len(bv))
+	}
+
+	return newTreeFS(files, func(name string) ([]byte, error) {
+		return snapshot[name], nil
+	}), nil
+}
+
+// MergeBase implements the VCS interface
+func (f *Fake) MergeBase(a, b string) (string, error) {
+	i, err := f.resolve(a)
+	if err != nil {
+		return "", err
+	}
+
+	j, err := f.resolve(b)
+	if err != nil {
+		return "", err
+	}
+
+	return f.Commits[min(i, j)].Hash, nil
+}
+
+// Diff implements the VCS interface
+func (f *Fake) Diff(from, to string) (files []string, err error) {
+	i, err := f.resolve(from)
+	if err != nil {
+		return
+	}
+
+	j, err := f.resolve(to)
+	if err != nil {
+		return
+	}
+
+	a, b := f.Commits[i].Files, f.Commits[j].Files
+	for p, bv := range b {
+		if old, ok := a[p]; !ok || string(old) != string(bv) {
+			files = append(files, p)
+		}
+	}
+	for p := range a {
+		if _, ok := b[p]; !ok {
+			files = append(files, p)
+		}
+	}
+
+	sort.Strings(files)
+	return
+}
+
+// resolve returns the index of the commit referred to by the given hash, tag
+// name, `HEAD` or `HEAD~N`. An empty revision stands for HEAD
+func (f *Fake) resolve(rev string) (int, error) {
+	if f.Err != nil {
+		return -1, f.Err
+	}
+
+	if rev == "" {
+		rev = "HEAD"
+	}
+
+	if rev == "HEAD" || strings.HasPrefix(rev, "HEAD~") {
+		n := 0
+		if rev != "HEAD" {
+			if _, err := fmt.Sscanf(rev, "HEAD~%d", &n); err != nil {
+				return -1, fmt.Errorf("fatal: bad revision '%v'", rev)
+			}
+		}
+
+		if i := len(f.Commits) - 1 - n; i >= 0 {
+			return i, nil
+		}
+		return -1, fmt.Errorf("fatal: bad revision '%v'", rev)
+	}
+
+	for _, t := range f.TagList {
+		if t.Name == rev {
+			return f.index(t.Commit), nil
+		}
+	}
+
+	if i := f.index(rev); i >= 0 {
+		return i, nil
+	}
+	return -1, fmt.Errorf("fatal: bad revision '%v'", rev)
+}
+
+// index returns the index of the commit with the given hash, or -1
+func (f *Fake) index(hash string) int {
+	for i, c := range f.Commits {
+		if c.Hash == hash {
+			return i
+		}
+	}
+	return -1
+}
+
+func (f *Fake) now() time.Time {
+	if f.Now != nil {
+		return f.Now()
+	}
+	return time.Now()
+}
@@ -0,0 +1,274 @@
+package vcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jwmwalrus/bnp/git"
+)
+
+const (
+	fieldSep  = "\x1f"
+	recordSep = "\x1e"
+)
+
+// Git implements VCS through the git command
+type Git struct {
+	h git.Handler
+}
+
+var _ VCS = (*Git)(nil)
+
+// NewGit returns the Git VCS of the repository containing dir
+func NewGit(dir string) (g *Git, err error) {
+	h, err := git.NewHandler(dir)
+	if err != nil {
+		return
+	}
+
+	g = &Git{h: h}
+	return
+}
+
+// TopLevel implements the VCS interface
+func (g *Git) TopLevel() string {
+	return g.h.TopLevel()
+}
+
+// LatestTag implements the VCS interface
+func (g *Git) LatestTag(noFetch bool) (string, error) {
+	return g.h.LatestTag(noFetch)
+}
+
+// CommitFiles implements the VCS interface. Only the given files are
+// committed, while any other staged change is kept staged
+func (g *Git) CommitFiles(files []string, msg string) (err error) {
+	paths := make([]string, len(files))
+	for i, f := range files {
+		if paths[i], err = filepath.Abs(f); err != nil {
+			return
+		}
+	}
+
+	if _, err = g.git(append([]string{"add", "--"}, paths...)...); err != nil {
+		return
+	}
+
+	if _, err = g.git(append([]string{"commit", "--message", msg, "--only", "--"}, paths...)...); err != nil {
+		g.git(append([]string{"reset", "--quiet", "--"}, paths...)...)
+	}
+	return
+}
+
+// NewTag implements the VCS interface
+func (g *Git) NewTag(name, msg string) error {
+	return g.h.NewTag(name, msg)
+}
+
+// Status implements the VCS interface
+func (g *Git) Status() (staged, unstaged, untracked []string, err error) {
+	return g.h.Status()
+}
+
+// TreeFS implements the VCS interface
+func (g *Git) TreeFS(rev string) (fs.FS, error) {
+	out, err := g.git("ls-tree", "-r", "-z", "--long", rev)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]int64{}
+	for _, rec := range strings.Split(string(out), "\x00") {
+		meta, p, ok := strings.Cut(rec, "\t")
+		f := strings.Fields(meta)
+		if !ok || len(f) < 4 || f[1] != "blob" {
+			continue
+		}
+		files[p], _ = strconv.ParseInt(f[3], 10, 64)
+	}
+
+	return newTreeFS(files, func(name string) ([]byte, error) {
+		return g.Show(rev, name)
+	}), nil
+}
+
+// Tags implements the VCS interface
+func (g *Git) Tags() (list []Tag, err error) {
+	format := strings.Join([]string{
+		"%(refname:short)",
+		"%(objecttype)",
+		"%(objectname)",
+		"%(*objectname)",
+		"%(creatordate:unix)",
+		"%(taggername) %(taggeremail)",
+		"%(contents:subject)",
+		"%(contents)",
+	}, "%1f") + "%1e"
+
+	out, err := g.git("for-each-ref", "--format="+format, "refs/tags")
+	if err != nil {
+		return
+	}
+
+	for _, rec := range strings.Split(string(out), recordSep) {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+
+		f := strings.SplitN(rec, fieldSep, 8)
+		if len(f) < 8 {
+			continue
+		}
+
+		ts, _ := strconv.ParseInt(f[4], 10, 64)
+		t := Tag{
+			Name:      f[0],
+			Annotated: f[1] == "tag",
+			Commit:    f[2],
+			Date:      time.Unix(ts, 0),
+		}
+
+		if t.Annotated {
+			t.Commit = f[3]
+			t.Tagger = strings.TrimSpace(f[5])
+			t.Subject = f[6]
+			t.Message = strings.TrimSpace(f[7])
+		}
+
+		list = append(list, t)
+	}
+
+	return
+}
+
+// Log implements the VCS interface
+func (g *Git) Log(from, to string) (list []Commit, err error) {
+	if to == "" {
+		to = "HEAD"
+	}
+
+	rng := to
+	if from != "" {
+		rng = from + ".." + to
+	}
+
+	return g.log(rng)
+}
+
+// Head implements the VCS interface
+func (g *Git) Head() (c Commit, err error) {
+	list, err := g.log("-1", "HEAD")
+	if err != nil {
+		return
+	}
+
+	if len(list) == 0 {
+		err = errors.New("Unable to obtain HEAD commit")
+		return
+	}
+
+	c = list[0]
+	return
+}
+
+// Branch implements the VCS interface
+func (g *Git) Branch() (name string, err error) {
+	out, err := g.git("branch", "--show-current")
+	if err != nil {
+		return
+	}
+	name = strings.TrimSpace(string(out))
+	return
+}
+
+func (g *Git) log(args ...string) (list []Commit, err error) {
+	args = append([]string{"log", "--format=%H" + fieldSep + "%at" + fieldSep + "%an" + fieldSep + "%s" + fieldSep + "%b" + recordSep}, args...)
+
+	out, err := g.git(args...)
+	if err != nil {
+		return
+	}
+
+	for _, rec := range strings.Split(string(out), recordSep) {
+		rec = strings.TrimLeft(rec, "\n")
+		if rec == "" {
+			continue
+		}
+
+		f := strings.SplitN(rec, fieldSep, 5)
+		if len(f) < 5 {
+			continue
+		}
+
+		ts, _ := strconv.ParseInt(f[1], 10, 64)
+		list = append(list, Commit{
+			Hash:    f[0],
+			Time:    time.Unix(ts, 0),
+			Author:  f[2],
+			Subject: f[3],
+			Body:    strings.TrimSpace(f[4]),
+		})
+	}
+
+	return
+}
+
+// MergeBase implements the VCS interface
+func (g *Git) MergeBase(a, b string) (hash string, err error) {
+	out, err := g.git("merge-base", a, b)
+	if err != nil {
+		return
+	}
+	hash = strings.TrimSpace(string(out))
+	return
+}
+
+// Diff implements the VCS interface
+func (g *Git) Diff(from, to string) (files []string, err error) {
+	if to == "" {
+		to = "HEAD"
+	}
+
+	out, err := g.git("diff", "--name-only", "-z", from, to)
+	if err != nil {
+		return
+	}
+
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" {
+			files = append(files, f)
+		}
+	}
+	return
+}
+
+// Show implements the VCS interface
+func (g *Git) Show(rev, path string) ([]byte, error) {
+	return g.git("show", rev+":"+path)
+}
+
+func (g *Git) git(args ...string) ([]byte, error) {
+	return execGit(g.TopLevel(), args...)
+}
+
+func execGit(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	outb := &bytes.Buffer{}
+	errb := &bytes.Buffer{}
+	cmd.Stdout = outb
+	cmd.Stderr = errb
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.TrimSpace(errb.String()), err)
+	}
+
+	return outb.Bytes(), nil
+}
@@ -0,0 +1,742 @@
+package vcs
+
+import (
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	gogit "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/format/index"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/revlist"
+	"github.com/go-git/go-git/v5/plumbing/storer"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+)
+
+// GoGit implements VCS in pure Go, without requiring the git command
+type GoGit struct {
+	repo *gogit.Repository
+	root string
+}
+
+var _ VCS = (*GoGit)(nil)
+
+// NewGoGit returns the GoGit VCS of the repository containing dir
+func NewGoGit(dir string) (g *GoGit, err error) {
+	repo, err := gogit.PlainOpenWithOptions(dir, &gogit.PlainOpenOptions{DetectDotGit: true})
+	if err != nil {
+		return
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return
+	}
+
+	g = &GoGit{repo: repo, root: wt.Filesystem.Root()}
+	return
+}
+
+// TopLevel implements the VCS interface
+func (g *GoGit) TopLevel() string {
+	return g.root
+}
+
+// LatestTag implements the VCS interface
+func (g *GoGit) LatestTag(noFetch bool) (tag string, err error) {
+	if !noFetch {
+		if err = g.fetch(); err != nil {
+			return
+		}
+	}
+
+	tags, err := g.Tags()
+	if err != nil {
+		return
+	}
+
+	var latest *Tag
+	var latestTime time.Time
+	for i, t := range tags {
+		c, cErr := g.repo.CommitObject(plumbing.NewHash(t.Commit))
+		if cErr != nil {
+			continue
+		}
+
+		// like git-describe, prefer the most recent commit, then annotated
+		// tags, then the most recent tag
+		when := c.Committer.When
+		switch {
+		case latest == nil, when.After(latestTime):
+		case !when.Equal(latestTime), t.Commit != latest.Commit:
+			continue
+		case t.Annotated != latest.Annotated:
+			if !t.Annotated {
+				continue
+			}
+		case !t.Date.After(latest.Date):
+			continue
+		}
+
+		latest = &tags[i]
+		latestTime = when
+	}
+
+	if latest == nil {
+		err = errors.New("No names found, cannot describe anything")
+		return
+	}
+
+	tag = latest.Name
+	return
+}
+
+// Tags implements the VCS interface
+func (g *GoGit) Tags() (list []Tag, err error) {
+	iter, err := g.repo.Tags()
+	if err != nil {
+		return
+	}
+
+	err = iter.ForEach(func(ref *plumbing.Reference) error {
+		t := Tag{Name: ref.Name().Short()}
+
+		if obj, err := g.repo.TagObject(ref.Hash()); err == nil {
+			c, err := obj.Commit()
+			if err != nil {
+				return nil
+			}
+
+			msg := strings.TrimSpace(obj.Message)
+			t.Annotated = true
+			t.Commit = c.Hash.String()
+			t.Date = obj.Tagger.When
+			t.Tagger = obj.Tagger.Name + " <" + obj.Tagger.Email + ">"
+			t.Subject, _, _ = strings.Cut(msg, "\n")
+			t.Message = msg
+		} else if errors.Is(err, plumbing.ErrObjectNotFound) {
+			c, err := g.repo.CommitObject(ref.Hash())
+			if err != nil {
+				return nil
+			}
+
+			t.Commit = c.Hash.String()
+			t.Date = c.Committer.When
+		} else {
+			return err
+		}
+
+		list = append(list, t)
+		return nil
+	})
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return
+}
+
+// CommitFiles implements the VCS interface. Only the given files are
+// committed, while any other staged change is kept staged
+func (g *GoGit) CommitFiles(files []string, msg string) (err error) {
+	sig, err := g.signature()
+	if err != nil {
+		return
+	}
+
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return
+	}
+
+	paths := map[string]bool{}
+	for _, f := range files {
+		var rel string
+		if rel, err = g.rel(f); err != nil {
+			return
+		}
+		paths[rel] = true
+	}
+
+	before, err := g.repo.Storer.Index()
+	if err != nil {
+		return
+	}
+
+	head := map[string]*index.Entry{}
+	if h, hErr := g.repo.Head(); hErr == nil {
+		var tree *object.Tree
+		if tree, err = g.commitTree(h.Hash()); err != nil {
+			return
+		}
+
+		err = tree.Files().ForEach(func(f *object.File) error {
+			head[f.Name] = &index.Entry{Name: f.Name, Hash: f.Hash, Mode: f.Mode}
+			return nil
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	// stage the HEAD version of every other path, so that only the given
+	// files make it to the commit
+	staged := &index.Index{Version: before.Version}
+	seen := map[string]bool{}
+	for _, e := range before.Entries {
+		seen[e.Name] = true
+		if paths[e.Name] {
+			staged.Entries = append(staged.Entries, e)
+		} else if h, ok := head[e.Name]; ok {
+			ce := *e
+			ce.Hash, ce.Mode = h.Hash, h.Mode
+			staged.Entries = append(staged.Entries, &ce)
+		}
+	}
+	for name, h := range head {
+		if !seen[name] && !paths[name] {
+			staged.Entries = append(staged.Entries, h)
+		}
+	}
+	sortEntries(staged)
+
+	if err = g.repo.Storer.SetIndex(staged); err != nil {
+		return
+	}
+
+	defer func() {
+		// restore the staged changes of every other path
+		after, idxErr := g.repo.Storer.Index()
+		if idxErr != nil {
+			if err == nil {
+				err = idxErr
+			}
+			return
+		}
+
+		restored := &index.Index{Version: after.Version}
+		for _, e := range after.Entries {
+			if paths[e.Name] {
+				restored.Entries = append(restored.Entries, e)
+			}
+		}
+		for _, e := range before.Entries {
+			if !paths[e.Name] {
+				restored.Entries = append(restored.Entries, e)
+			}
+		}
+		sortEntries(restored)
+
+		if idxErr = g.repo.Storer.SetIndex(restored); idxErr != nil && err == nil {
+			err = idxErr
+		}
+	}()
+
+	for p := range paths {
+		if _, err = wt.Add(p); err != nil {
+			return
+		}
+	}
+
+	_, err = wt.Commit(msg, &gogit.CommitOptions{Author: sig, Committer: sig})
+	return
+}
+
+// NewTag implements the VCS interface
+func (g *GoGit) NewTag(name, msg string) (err error) {
+	sig, err := g.signature()
+	if err != nil {
+		return
+	}
+
+	head, err := g.repo.Head()
+	if err != nil {
+		return
+	}
+
+	_, err = g.repo.CreateTag(name, head.Hash(), &gogit.CreateTagOptions{
+		Tagger:  sig,
+		Message: msg,
+	})
+	return
+}
+
+// Log implements the VCS interface
+func (g *GoGit) Log(from, to string) (list []Commit, err error) {
+	if to == "" {
+		to = "HEAD"
+	}
+
+	toHash, err := g.resolve(to)
+	if err != nil {
+		return
+	}
+
+	exclude := map[plumbing.Hash]bool{}
+	if from != "" {
+		var fromHash plumbing.Hash
+		if fromHash, err = g.resolve(from); err != nil {
+			return
+		}
+
+		var iter object.CommitIter
+		if iter, err = g.repo.Log(&gogit.LogOptions{From: fromHash}); err != nil {
+			return
+		}
+		err = iter.ForEach(func(c *object.Commit) error {
+			exclude[c.Hash] = true
+			return nil
+		})
+		if err != nil {
+			return
+		}
+	}
+
+	iter, err := g.repo.Log(&gogit.LogOptions{From: toHash, Order: gogit.LogOrderCommitterTime})
+	if err != nil {
+		return
+	}
+
+	err = iter.ForEach(func(c *object.Commit) error {
+		if !exclude[c.Hash] {
+			list = append(list, toCommit(c))
+		}
+		return nil
+	})
+	return
+}
+
+// Head implements the VCS interface
+func (g *GoGit) Head() (c Commit, err error) {
+	h, err := g.repo.Head()
+	if err != nil {
+		err = errors.New("Unable to obtain HEAD commit")
+		return
+	}
+
+	obj, err := g.repo.CommitObject(h.Hash())
+	if err != nil {
+		return
+	}
+
+	c = toCommit(obj)
+	return
+}
+
+// Branch implements the VCS interface
+func (g *GoGit) Branch() (name string, err error) {
+	ref, err := g.repo.Storer.Reference(plumbing.HEAD)
+	if err != nil {
+		return
+	}
+
+	if ref.Type() == plumbing.SymbolicReference && ref.Target().IsBranch() {
+		name = ref.Target().Short()
+	}
+	return
+}
+
+// Status implements the VCS interface
+func (g *GoGit) Status() (staged, unstaged, untracked []string, err error) {
+	wt, err := g.repo.Worktree()
+	if err != nil {
+		return
+	}
+
+	st, err := wt.Status()
+	if err != nil {
+		return
+	}
+
+	for p, s := range st {
+		switch {
+		case s.Worktree == gogit.Untracked:
+			untracked = append(untracked, p)
+			continue
+		case s.Staging != gogit.Unmodified:
+			staged = append(staged, p)
+		}
+
+		if s.Worktree != gogit.Unmodified {
+			unstaged = append(unstaged, p)
+		}
+	}
+
+	sort.Strings(staged)
+	sort.Strings(unstaged)
+	sort.Strings(untracked)
+	return
+}
+
+// Show implements the VCS interface
+func (g *GoGit) Show(rev, path string) (bv []byte, err error) {
+	h, err := g.resolve(rev)
+	if err != nil {
+		return
+	}
+
+	tree, err := g.commitTree(h)
+	if err != nil {
+		return
+	}
+
+	f, err := tree.File(path)
+	if err != nil {
+		return
+	}
+
+	r, err := f.Reader()
+	if err != nil {
+		return
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// TreeFS implements the VCS interface
+func (g *GoGit) TreeFS(rev string) (fs.FS, error) {
+	h, err := g.resolve(rev)
+	if err != nil {
+		return nil, err
+	}
+
+	tree, err := g.commitTree(h)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]int64{}
+	err = tree.Files().ForEach(func(f *object.File) error {
+		files[f.Name] = f.Size
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return newTreeFS(files, func(name string) ([]byte, error) {
+		return g.Show(h.String(), name)
+	}), nil
+}
+
+// MergeBase implements the VCS interface
+func (g *GoGit) MergeBase(a, b string) (hash string, err error) {
+	ca, err := g.commit(a)
+	if err != nil {
+		return
+	}
+
+	cb, err := g.commit(b)
+	if err != nil {
+		return
+	}
+
+	bases, err := ca.MergeBase(cb)
+	if err != nil {
+		return
+	}
+
+	if len(bases) == 0 {
+		err = errors.New("No common ancestor found")
+		return
+	}
+
+	hash = bases[0].Hash.String()
+	return
+}
+
+// Diff implements the VCS interface
+func (g *GoGit) Diff(from, to string) (files []string, err error) {
+	if to == "" {
+		to = "HEAD"
+	}
+
+	fh, err := g.resolve(from)
+	if err != nil {
+		return
+	}
+
+	th, err := g.resolve(to)
+	if err != nil {
+		return
+	}
+
+	ft, err := g.commitTree(fh)
+	if err != nil {
+		return
+	}
+
+	tt, err := g.commitTree(th)
+	if err != nil {
+		return
+	}
+
+	changes, err := ft.Diff(tt)
+	if err != nil {
+		return
+	}
+
+	seen := map[string]bool{}
+	for _, ch := range changes {
+		for _, name := range []string{ch.From.Name, ch.To.Name} {
+			if name != "" && !seen[name] {
+				seen[name] = true
+				files = append(files, name)
+			}
+		}
+	}
+
+	sort.Strings(files)
+	return
+}
+
+// fetch fetches the branches and tags of the default remote, if any
+func (g *GoGit) fetch() (err error) {
+	remotes, err := g.repo.Remotes()
+	if err != nil || len(remotes) == 0 {
+		return
+	}
+
+	rc := remotes[0].Config()
+	for _, r := range remotes {
+		if r.Config().Name == gogit.DefaultRemoteName {
+			rc = r.Config()
+		}
+	}
+
+	if path, ok := g.localPath(rc); ok {
+		return g.fetchLocal(rc, path)
+	}
+
+	err = g.repo.Fetch(&gogit.FetchOptions{RemoteName: rc.Name, Tags: gogit.AllTags})
+	if errors.Is(err, gogit.NoErrAlreadyUpToDate) {
+		err = nil
+	}
+	return
+}
+
+// fetchLocal fetches from a remote in the local filesystem. The remote
+// repository is read directly, since go-git would otherwise require
+// git-upload-pack
+func (g *GoGit) fetchLocal(rc *gitconfig.RemoteConfig, path string) (err error) {
+	src, err := gogit.PlainOpen(path)
+	if err != nil {
+		return
+	}
+
+	refs, err := src.References()
+	if err != nil {
+		return
+	}
+
+	updates := map[plumbing.ReferenceName]plumbing.Hash{}
+	err = refs.ForEach(func(ref *plumbing.Reference) error {
+		if ref.Type() != plumbing.HashReference {
+			return nil
+		}
+
+		name := ref.Name()
+		if name.IsTag() {
+			// like git, do not clobber existing tags
+			if _, err := g.repo.Reference(name, false); err != nil {
+				updates[name] = ref.Hash()
+			}
+			return nil
+		}
+
+		for _, spec := range rc.Fetch {
+			if spec.Match(name) {
+				updates[spec.Dst(name)] = ref.Hash()
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return
+	}
+
+	return transfer(src, g.repo, updates)
+}
+
+// signature returns the identity used for commits and tags, honoring the
+// GIT_AUTHOR_NAME and GIT_AUTHOR_EMAIL environment variables
+func (g *GoGit) signature() (sig *object.Signature, err error) {
+	cfg, err := g.repo.ConfigScoped(gitconfig.GlobalScope)
+	if err != nil {
+		return
+	}
+
+	sig = &object.Signature{
+		Name:  cfg.User.Name,
+		Email: cfg.User.Email,
+		When:  time.Now(),
+	}
+
+	if v := os.Getenv("GIT_AUTHOR_NAME"); v != "" {
+		sig.Name = v
+	}
+	if v := os.Getenv("GIT_AUTHOR_EMAIL"); v != "" {
+		sig.Email = v
+	}
+
+	if sig.Name == "" || sig.Email == "" {
+		err = errors.New("Author identity unknown, please set user.name and user.email")
+	}
+	return
+}
+
+func (g *GoGit) resolve(rev string) (h plumbing.Hash, err error) {
+	ph, err := g.repo.ResolveRevision(plumbing.Revision(rev))
+	if err != nil {
+		return
+	}
+
+	// peel annotated tags
+	if obj, tErr := g.repo.TagObject(*ph); tErr == nil {
+		var c *object.Commit
+		if c, err = obj.Commit(); err != nil {
+			return
+		}
+		return c.Hash, nil
+	}
+
+	return *ph, nil
+}
+
+func (g *GoGit) commit(rev string) (c *object.Commit, err error) {
+	h, err := g.resolve(rev)
+	if err != nil {
+		return
+	}
+	return g.repo.CommitObject(h)
+}
+
+func (g *GoGit) commitTree(h plumbing.Hash) (tree *object.Tree, err error) {
+	c, err := g.repo.CommitObject(h)
+	if err != nil {
+		return
+	}
+	return c.Tree()
+}
+
+// rel returns the path of the given file, relative to the top-level
+// directory
+func (g *GoGit) rel(file string) (rel string, err error) {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return
+	}
+
+	if rel, err = filepath.Rel(g.root, abs); err != nil {
+		return
+	}
+	rel = filepath.ToSlash(rel)
+	return
+}
+
+// transfer copies the objects required by the given references from one
+// repository to another, and then updates the references
+func transfer(from, to *gogit.Repository, updates map[plumbing.ReferenceName]plumbing.Hash) (err error) {
+	var wants []plumbing.Hash
+	for _, h := range updates {
+		if to.Storer.HasEncodedObject(h) != nil {
+			wants = append(wants, h)
+		}
+	}
+
+	if len(wants) > 0 {
+		var haves []plumbing.Hash
+		var refs storer.ReferenceIter
+		if refs, err = to.References(); err != nil {
+			return
+		}
+		err = refs.ForEach(func(ref *plumbing.Reference) error {
+			if ref.Type() == plumbing.HashReference {
+				haves = append(haves, ref.Hash())
+			}
+			return nil
+		})
+		if err != nil {
+			return
+		}
+
+		var objs []plumbing.Hash
+		objs, err = revlist.ObjectsWithStorageForIgnores(from.Storer, to.Storer, wants, haves)
+		if err != nil {
+			return
+		}
+
+		for _, h := range objs {
+			if to.Storer.HasEncodedObject(h) == nil {
+				continue
+			}
+
+			var obj plumbing.EncodedObject
+			if obj, err = from.Storer.EncodedObject(plumbing.AnyObject, h); err != nil {
+				return
+			}
+			if _, err = to.Storer.SetEncodedObject(obj); err != nil {
+				return
+			}
+		}
+	}
+
+	for name, h := range updates {
+		if err = to.Storer.SetReference(plumbing.NewHashReference(name, h)); err != nil {
+			return
+		}
+	}
+	return
+}
+
+// localPath returns the path of the given remote, if it lives in the local
+// filesystem
+func (g *GoGit) localPath(rc *gitconfig.RemoteConfig) (string, bool) {
+	if len(rc.URLs) == 0 {
+		return "", false
+	}
+
+	// relative paths are relative to the top-level directory, not to cwd
+	url := rc.URLs[0]
+	if !strings.Contains(url, "://") && !filepath.IsAbs(url) {
+		if p := filepath.Join(g.root, url); exists(p) {
+			return p, true
+		}
+	}
+
+	ep, err := transport.NewEndpoint(url)
+	if err != nil || ep.Protocol != "file" {
+		return "", false
+	}
+	return ep.Path, true
+}
+
+func exists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+func toCommit(c *object.Commit) Commit {
+	msg := strings.TrimSpace(c.Message)
+	subject, body, _ := strings.Cut(msg, "\n")
+
+	return Commit{
+		Hash:    c.Hash.String(),
+		Time:    c.Author.When,
+		Author:  c.Author.Name,
+		Subject: subject,
+		Body:    strings.TrimSpace(body),
+	}
+}
+
+func sortEntries(idx *index.Index) {
+	sort.Slice(idx.Entries, func(i, j int) bool {
+		return idx.Entries[i].Name < idx.Entries[j].Name
+	})
+}
@@ -0,0 +1,275 @@
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// backends opens a repository with each of the git backends, which are
+// expected to behave the same
+var backends = []struct {
+	name string
+	open func(dir string) (VCS, error)
+}{
+	{BackendGit, func(dir string) (VCS, error) { return NewGit(dir) }},
+	{BackendGoGit, func(dir string) (VCS, error) { return NewGoGit(dir) }},
+}
+
+// gitEnv isolates the git command from the user and system configuration,
+// and skips the test if git is not available
+func gitEnv(t *testing.T) {
+	t.Helper()
+
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not available")
+	}
+
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", home)
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	for _, who := range []string{"AUTHOR", "COMMITTER"} {
+		t.Setenv("GIT_"+who+"_NAME", "Tester")
+		t.Setenv("GIT_"+who+"_EMAIL", "tester@example.com")
+	}
+}
+
+// newGitRepo creates a repository with the git command, with an initial
+// commit of the given files
+func newGitRepo(t *testing.T, files ...string) string {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runGit(t, dir, "init", "--quiet", "--initial-branch", "main")
+	setDate(t, 0)
+	for _, f := range files {
+		writeFile(t, dir, f, f+"\n")
+	}
+	runGit(t, dir, "add", "--all")
+	runGit(t, dir, "commit", "--quiet", "--allow-empty", "--message", "Initial commit")
+	return dir
+}
+
+// runGit runs the git command at dir, and returns its trimmed output
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := execGit(dir, args...)
+	if err != nil {
+		t.Fatalf("git %v: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// setDate dates the next commits and tags n hours after the initial commit
+func setDate(t *testing.T, n int) {
+	date := fmt.Sprintf("%d +0000", 1700000000+n*3600)
+	t.Setenv("GIT_AUTHOR_DATE", date)
+	t.Setenv("GIT_COMMITTER_DATE", date)
+}
+
+// commitAt commits a change of the given file, dated n hours after the
+// initial commit
+func commitAt(t *testing.T, dir, file string, n int) {
+	t.Helper()
+
+	setDate(t, n)
+	writeFile(t, dir, file, fmt.Sprintf("%v %v\n", file, n))
+	runGit(t, dir, "add", file)
+	runGit(t, dir, "commit", "--quiet", "--message", fmt.Sprintf("Change %v", n))
+}
+
+func writeFile(t *testing.T, dir, name, content string) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommitFilesKeepsStaged(t *testing.T) {
+	gitEnv(t)
+
+	for _, b := range backends {
+		dir := newGitRepo(t, "a.txt", "b.txt", "c.txt")
+		writeFile(t, dir, "a.txt", "a changed\n")
+		writeFile(t, dir, "b.txt", "b staged\n")
+		writeFile(t, dir, "c.txt", "c unstaged\n")
+		writeFile(t, dir, "d.txt", "d new\n")
+		runGit(t, dir, "add", "b.txt")
+
+		v, err := b.open(dir)
+		if err != nil {
+			t.Fatalf("%v: %v", b.name, err)
+		}
+
+		files := []string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "d.txt")}
+		if err = v.CommitFiles(files, "Bump version"); err != nil {
+			t.Fatalf("%v: %v", b.name, err)
+		}
+
+		if got := runGit(t, dir, "log", "-1", "--format=%s"); got != "Bump version" {
+			t.Errorf("%v: subject = %q, want %q", b.name, got, "Bump version")
+		}
+		if got := runGit(t, dir, "show", "--name-only", "--format=", "HEAD"); got != "a.txt\nd.txt" {
+			t.Errorf("%v: committed %q, want a.txt and d.txt", b.name, got)
+		}
+		if got := runGit(t, dir, "show", "HEAD:a.txt"); got != "a changed" {
+			t.Errorf("%v: committed a.txt = %q", b.name, got)
+		}
+		if got := runGit(t, dir, "diff", "--cached", "--name-only"); got != "b.txt" {
+			t.Errorf("%v: staged %q, want b.txt", b.name, got)
+		}
+		if got := runGit(t, dir, "diff", "--name-only"); got != "c.txt" {
+			t.Errorf("%v: unstaged %q, want c.txt", b.name, got)
+		}
+	}
+}
+
+func TestNewTagAnnotated(t *testing.T) {
+	gitEnv(t)
+
+	const msg = "Release 1.0.0\n\n- Something new"
+
+	for _, b := range backends {
+		dir := newGitRepo(t, "a.txt")
+
+		v, err := b.open(dir)
+		if err != nil {
+			t.Fatalf("%v: %v", b.name, err)
+		}
+
+		if err = v.NewTag("v1.0.0", msg); err != nil {
+			t.Fatalf("%v: %v", b.name, err)
+		}
+		if err = v.NewTag("v1.0.0", msg); err == nil {
+			t.Errorf("%v: expected error for an existing tag", b.name)
+		}
+
+		if got := runGit(t, dir, "cat-file", "-t", "v1.0.0"); got != "tag" {
+			t.Errorf("%v: tag object type = %q, want annotated", b.name, got)
+		}
+		if got := runGit(t, dir, "tag", "--list", "--format=%(contents)", "v1.0.0"); got != msg {
+			t.Errorf("%v: tag message = %q, want %q", b.name, got, msg)
+		}
+		if got, head := runGit(t, dir, "rev-parse", "v1.0.0^{commit}"), runGit(t, dir, "rev-parse", "HEAD"); got != head {
+			t.Errorf("%v: tagged %v, want HEAD %v", b.name, got, head)
+		}
+
+		for _, o := range backends {
+			ov, err := o.open(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			tags, err := ov.Tags()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tags) != 1 || !tags[0].Annotated || tags[0].Message != msg || tags[0].Subject != "Release 1.0.0" || tags[0].Tagger != "Tester <tester@example.com>" {
+				t.Errorf("%v tag read by %v: %+v", b.name, o.name, tags)
+			}
+		}
+	}
+}
+
+func TestLatestTag(t *testing.T) {
+	gitEnv(t)
+
+	dir := newGitRepo(t)
+	commitAt(t, dir, "a.txt", 1)
+	runGit(t, dir, "tag", "--annotate", "v1.0.0", "--message", "Release 1.0.0")
+	commitAt(t, dir, "a.txt", 2)
+	runGit(t, dir, "tag", "v1.1.0")
+
+	runGit(t, dir, "checkout", "--quiet", "-b", "next")
+	commitAt(t, dir, "b.txt", 5)
+	runGit(t, dir, "tag", "nightly")
+	runGit(t, dir, "tag", "--annotate", "v2.0.0-rc.1", "--message", "Release 2.0.0-rc.1")
+	runGit(t, dir, "tag", "zz-lightweight")
+
+	runGit(t, dir, "checkout", "--quiet", "main")
+	commitAt(t, dir, "a.txt", 3)
+	runGit(t, dir, "tag", "--annotate", "v1.2.0", "--message", "Release 1.2.0")
+
+	// the most recent commit is on another branch, and carries annotated and
+	// lightweight tags
+	want := runGit(t, dir, "describe", "--tags", runGit(t, dir, "rev-list", "--tags", "--max-count=1"))
+	if want != "v2.0.0-rc.1" {
+		t.Fatalf("git describe = %v, want v2.0.0-rc.1", want)
+	}
+
+	for _, b := range backends {
+		v, err := b.open(dir)
+		if err != nil {
+			t.Fatalf("%v: %v", b.name, err)
+		}
+
+		if got, err := v.LatestTag(true); err != nil || got != want {
+			t.Errorf("%v: latest tag = %q (%v), want %q", b.name, got, err, want)
+		}
+	}
+}
+
+func TestLatestTagFetch(t *testing.T) {
+	gitEnv(t)
+
+	origin := newGitRepo(t, "a.txt")
+	runGit(t, origin, "tag", "--annotate", "v1.0.0", "--message", "Release 1.0.0")
+
+	n, prev := 0, "v1.0.0"
+	for _, b := range backends {
+		for _, remote := range []string{"path", "file", "relative"} {
+			parent, err := filepath.EvalSymlinks(t.TempDir())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			clone := filepath.Join(parent, "clone")
+			runGit(t, parent, "clone", "--quiet", origin, clone)
+
+			switch remote {
+			case "file":
+				runGit(t, clone, "remote", "set-url", "origin", "file://"+filepath.ToSlash(origin))
+			case "relative":
+				rel, err := filepath.Rel(clone, origin)
+				if err != nil {
+					t.Fatal(err)
+				}
+				runGit(t, clone, "remote", "set-url", "origin", rel)
+			}
+
+			// a new release, only present in the remote
+			n++
+			tag := fmt.Sprintf("v1.%d.0", n)
+			commitAt(t, origin, "a.txt", n)
+			runGit(t, origin, "tag", "--annotate", tag, "--message", "Release "+tag)
+
+			v, err := b.open(clone)
+			if err != nil {
+				t.Fatalf("%v, %v: %v", b.name, remote, err)
+			}
+
+			if got, err := v.LatestTag(true); err != nil || got != prev {
+				t.Errorf("%v, %v: latest tag without fetch = %q (%v), want %q", b.name, remote, got, err, prev)
+			}
+
+			if got, err := v.LatestTag(false); err != nil || got != tag {
+				t.Errorf("%v, %v: latest tag = %q (%v), want %q", b.name, remote, got, err, tag)
+			}
+
+			if got := runGit(t, clone, "rev-parse", "origin/main"); got != runGit(t, origin, "rev-parse", "main") {
+				t.Errorf("%v, %v: origin/main not updated", b.name, remote)
+			}
+			prev = tag
+		}
+	}
+}
@@ -0,0 +1,449 @@
+package vcs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Hg implements VCS through the hg command. Tags are the global and local
+// Mercurial tags, with the former created through .hgtags commits
+type Hg struct {
+	root string
+}
+
+var _ VCS = (*Hg)(nil)
+
+// hgLogTemplate matches parseHgLog
+const hgLogTemplate = "{node}" + fieldSep + "{date|hgdate}" + fieldSep + "{author|person}" + fieldSep + "{desc}" + recordSep
+
+// NewHg returns the Hg VCS of the repository containing dir
+func NewHg(dir string) (h *Hg, err error) {
+	out, err := execHg(dir, "root")
+	if err != nil {
+		return
+	}
+
+	h = &Hg{root: strings.TrimSpace(string(out))}
+	return
+}
+
+// TopLevel implements the VCS interface
+func (h *Hg) TopLevel() string {
+	return h.root
+}
+
+// LatestTag implements the VCS interface
+func (h *Hg) LatestTag(noFetch bool) (tag string, err error) {
+	if !noFetch {
+		if _, pErr := h.hg("paths", "default"); pErr == nil {
+			if _, err = h.hg("pull", "--quiet", "default"); err != nil {
+				return
+			}
+		}
+	}
+
+	list, err := h.tags()
+	if err != nil {
+		return
+	}
+
+	// prefer the highest revision, then the most recent tag
+	var latest *hgTag
+	for i, t := range list {
+		if latest == nil || t.rev > latest.rev || (t.rev == latest.rev && t.Date.After(latest.Date)) {
+			latest = &list[i]
+		}
+	}
+
+	if latest == nil {
+		err = errors.New("No names found, cannot describe anything")
+		return
+	}
+
+	tag = latest.Name
+	return
+}
+
+// Tags implements the VCS interface. Global tags are reported as annotated,
+// with the details of the changeset that added them to .hgtags
+func (h *Hg) Tags() (list []Tag, err error) {
+	tags, err := h.tags()
+	if err != nil {
+		return
+	}
+
+	for _, t := range tags {
+		list = append(list, t.Tag)
+	}
+
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return
+}
+
+// CommitFiles implements the VCS interface. Untracked files are added
+func (h *Hg) CommitFiles(files []string, msg string) (err error) {
+	args := []string{"commit", "--addremove", "-m", msg, "--"}
+	for _, f := range files {
+		var abs string
+		if abs, err = filepath.Abs(f); err != nil {
+			return
+		}
+		args = append(args, abs)
+	}
+
+	_, err = h.hg(args...)
+	return
+}
+
+// NewTag implements the VCS interface. The tag is committed to .hgtags
+func (h *Hg) NewTag(name, msg string) (err error) {
+	_, err = h.hg("tag", "-m", msg, "--", name)
+	return
+}
+
+// Log implements the VCS interface. Tagging changesets, which only change
+// .hgtags, are left out, as tags are not part of the history with git
+func (h *Hg) Log(from, to string) (list []Commit, err error) {
+	revs := "::" + hgRevset(to)
+	if from != "" {
+		revs += " - ::" + hgRevset(from)
+	}
+
+	all, err := h.log("sort(" + revs + ", -date)")
+	if err != nil {
+		return
+	}
+
+	tagging, err := h.tagging(revs)
+	if err != nil {
+		return
+	}
+
+	for _, c := range all {
+		if !tagging[c.Hash] {
+			list = append(list, c)
+		}
+	}
+	return
+}
+
+// Head implements the VCS interface
+func (h *Hg) Head() (c Commit, err error) {
+	list, err := h.log(".")
+	if err != nil {
+		return
+	}
+
+	if len(list) == 0 || strings.Trim(list[0].Hash, "0") == "" {
+		err = errors.New("Unable to obtain HEAD commit")
+		return
+	}
+
+	c = list[0]
+	return
+}
+
+// Branch implements the VCS interface. The active bookmark, if any, takes
+// precedence over the named branch, since Mercurial has no detached state
+func (h *Hg) Branch() (name string, err error) {
+	out, err := h.hg("log", "-r", ".", "-T", "{activebookmark}"+fieldSep+"{branch}")
+	if err != nil {
+		return
+	}
+
+	bookmark, branch, _ := strings.Cut(string(out), fieldSep)
+	name = strings.TrimSpace(bookmark)
+	if name == "" {
+		name = strings.TrimSpace(branch)
+	}
+	return
+}
+
+// Status implements the VCS interface. Since Mercurial has no staging area,
+// added and removed files are reported as staged, and modified and missing
+// ones as unstaged
+func (h *Hg) Status() (staged, unstaged, untracked []string, err error) {
+	out, err := h.hg("status", "--print0")
+	if err != nil {
+		return
+	}
+
+	for _, rec := range strings.Split(string(out), "\x00") {
+		if len(rec) < 3 {
+			continue
+		}
+
+		switch p := rec[2:]; rec[0] {
+		case 'A', 'R':
+			staged = append(staged, p)
+		case 'M', '!':
+			unstaged = append(unstaged, p)
+		case '?':
+			untracked = append(untracked, p)
+		}
+	}
+	return
+}
+
+// Show implements the VCS interface
+func (h *Hg) Show(rev, path string) ([]byte, error) {
+	return h.hg("cat", "-r", hgRev(rev), "--", "path:"+path)
+}
+
+// TreeFS implements the VCS interface
+func (h *Hg) TreeFS(rev string) (fs.FS, error) {
+	rev = hgRev(rev)
+
+	out, err := h.hg("files", "-r", rev, "-T", "{size}"+fieldSep+"{path}"+recordSep)
+	if err != nil {
+		return nil, err
+	}
+
+	files := map[string]int64{}
+	for _, rec := range strings.Split(string(out), recordSep) {
+		size, name, ok := strings.Cut(rec, fieldSep)
+		if !ok {
+			continue
+		}
+
+		n, _ := strconv.ParseInt(size, 10, 64)
+		files[filepath.ToSlash(name)] = n
+	}
+
+	return newTreeFS(files, func(name string) ([]byte, error) {
+		return h.Show(rev, name)
+	}), nil
+}
+
+// MergeBase implements the VCS interface
+func (h *Hg) MergeBase(a, b string) (hash string, err error) {
+	out, err := h.hg("log", "-r", "ancestor("+hgRevset(a)+", "+hgRevset(b)+")", "-T", "{node}")
+	if err != nil {
+		return
+	}
+
+	if hash = strings.TrimSpace(string(out)); hash == "" {
+		err = errors.New("No common ancestor found")
+	}
+	return
+}
+
+// Diff implements the VCS interface. Changes to .hgtags are left out, as
+// tags are not part of the tree with git
+func (h *Hg) Diff(from, to string) (files []string, err error) {
+	out, err := h.hg("status", "--no-status", "--print0", "--rev", hgRev(from), "--rev", hgRev(to))
+	if err != nil {
+		return
+	}
+
+	for _, f := range strings.Split(string(out), "\x00") {
+		if f != "" && f != hgTagsFile {
+			files = append(files, filepath.ToSlash(f))
+		}
+	}
+
+	sort.Strings(files)
+	return
+}
+
+// hgTag is a Tag along with the revision number of the tagged changeset
+type hgTag struct {
+	Tag
+	rev int
+}
+
+// hgTagsFile is the file global tags are committed to
+const hgTagsFile = ".hgtags"
+
+// hgTagLine matches the lines added to .hgtags
+var hgTagLine = regexp.MustCompile(`^\+([0-9a-f]{40}) (.+)$`)
+
+// tags returns every tag but `tip`
+func (h *Hg) tags() (list []hgTag, err error) {
+	out, err := h.hg("tags", "-T", "{tag}"+fieldSep+"{node}"+fieldSep+"{rev}"+fieldSep+"{type}"+recordSep)
+	if err != nil {
+		return
+	}
+
+	for _, rec := range strings.Split(string(out), recordSep) {
+		f := strings.Split(strings.TrimLeft(rec, "\n"), fieldSep)
+		if len(f) < 4 || f[0] == "tip" {
+			continue
+		}
+
+		rev, _ := strconv.Atoi(f[2])
+		list = append(list, hgTag{
+			Tag: Tag{
+				Name:      f[0],
+				Commit:    f[1],
+				Annotated: f[3] != "local",
+			},
+			rev: rev,
+		})
+	}
+
+	if len(list) == 0 {
+		return
+	}
+
+	// the tagging changesets, from the history of .hgtags
+	tagging := map[string]Commit{}
+	taggers := map[string]string{}
+	out, err = h.hg(
+		"log", "-r", "file('path:.hgtags')",
+		"-T", "{node}"+fieldSep+"{date|hgdate}"+fieldSep+"{author}"+fieldSep+"{desc}"+fieldSep+"{diff('path:.hgtags')}"+recordSep,
+	)
+	if err != nil {
+		return
+	}
+
+	for _, rec := range strings.Split(string(out), recordSep) {
+		f := strings.SplitN(strings.TrimLeft(rec, "\n"), fieldSep, 5)
+		if len(f) < 5 {
+			continue
+		}
+
+		c := Commit{Hash: f[0], Time: hgTime(f[1])}
+		msg := strings.TrimSpace(f[3])
+		c.Subject, c.Body, _ = strings.Cut(msg, "\n")
+
+		for _, line := range strings.Split(f[4], "\n") {
+			if m := hgTagLine.FindStringSubmatch(line); m != nil {
+				name := strings.TrimSpace(m[2])
+				tagging[name] = c
+				taggers[name] = f[2]
+			}
+		}
+	}
+
+	for i, t := range list {
+		if c, ok := tagging[t.Name]; ok && t.Annotated {
+			list[i].Date = c.Time
+			list[i].Tagger = taggers[t.Name]
+			list[i].Subject = c.Subject
+			list[i].Message = strings.TrimSpace(c.Subject + "\n\n" + c.Body)
+			continue
+		}
+
+		// local tags, or global ones not found in the current .hgtags
+		// history, get the date of the tagged changeset
+		var cs []Commit
+		if cs, err = h.log(t.Commit); err != nil {
+			return
+		}
+		if len(cs) > 0 {
+			list[i].Date = cs[0].Time
+		}
+	}
+
+	return
+}
+
+// tagging returns the changesets of the given revset that only change
+// .hgtags, as created by `hg tag`
+func (h *Hg) tagging(revset string) (nodes map[string]bool, err error) {
+	out, err := h.hg("log", "-r", "("+revset+") and file('path:"+hgTagsFile+"')", "-T", "{node}"+fieldSep+"{files}"+recordSep)
+	if err != nil {
+		return
+	}
+
+	nodes = map[string]bool{}
+	for _, rec := range strings.Split(string(out), recordSep) {
+		node, files, ok := strings.Cut(strings.TrimLeft(rec, "\n"), fieldSep)
+		if ok && strings.TrimSpace(files) == hgTagsFile {
+			nodes[node] = true
+		}
+	}
+	return
+}
+
+func (h *Hg) log(revset string) (list []Commit, err error) {
+	out, err := h.hg("log", "-r", revset, "-T", hgLogTemplate)
+	if err != nil {
+		return
+	}
+
+	for _, rec := range strings.Split(string(out), recordSep) {
+		f := strings.SplitN(strings.TrimLeft(rec, "\n"), fieldSep, 4)
+		if len(f) < 4 {
+			continue
+		}
+
+		subject, body, _ := strings.Cut(strings.TrimSpace(f[3]), "\n")
+		list = append(list, Commit{
+			Hash:    f[0],
+			Time:    hgTime(f[1]),
+			Author:  f[2],
+			Subject: subject,
+			Body:    strings.TrimSpace(body),
+		})
+	}
+
+	return
+}
+
+func (h *Hg) hg(args ...string) ([]byte, error) {
+	return execHg(h.root, args...)
+}
+
+// hgRev translates a git-like revision into a Mercurial one. An empty
+// revision, `HEAD` and `HEAD~N` refer to the working directory's parent
+func hgRev(rev string) string {
+	switch {
+	case rev == "", rev == "HEAD":
+		return "."
+	case strings.HasPrefix(rev, "HEAD~"):
+		return "." + strings.TrimPrefix(rev, "HEAD")
+	}
+	return rev
+}
+
+// hgHeadRev matches the revisions produced by hgRev for HEAD
+var hgHeadRev = regexp.MustCompile(`^\.(~\d+)?$`)
+
+// hgRevset returns the given revision as a revset expression, quoting
+// symbols such as tag names
+func hgRevset(rev string) string {
+	rev = hgRev(rev)
+	if hgHeadRev.MatchString(rev) {
+		return rev
+	}
+	return strconv.Quote(rev)
+}
+
+// hgTime parses a date in `hgdate` format, i.e., a Unix timestamp followed
+// by the offset in seconds west of UTC
+func hgTime(s string) time.Time {
+	f := strings.Fields(s)
+	if len(f) == 0 {
+		return time.Time{}
+	}
+
+	ts, _ := strconv.ParseInt(f[0], 10, 64)
+	return time.Unix(ts, 0)
+}
+
+func execHg(dir string, args ...string) ([]byte, error) {
+	cmd := exec.Command("hg", append([]string{"--cwd", dir}, args...)...)
+	cmd.Env = append(os.Environ(), "HGPLAIN=1")
+	outb := &bytes.Buffer{}
+	errb := &bytes.Buffer{}
+	cmd.Stdout = outb
+	cmd.Stderr = errb
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w", strings.TrimSpace(errb.String()), err)
+	}
+
+	return outb.Bytes(), nil
+}
@@ -0,0 +1,242 @@
+package vcs
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// newHgRepo creates a repository with the hg command, isolated from the user
+// configuration, with an initial commit of the given files. The test is
+// skipped if hg is not available
+func newHgRepo(t *testing.T, files ...string) (string, *Hg) {
+	t.Helper()
+
+	if _, err := exec.LookPath("hg"); err != nil {
+		t.Skip("hg not available")
+	}
+
+	t.Setenv("HGRCPATH", "")
+	t.Setenv("HGUSER", "Tester <tester@example.com>")
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	runHg(t, dir, "init")
+	for _, f := range files {
+		writeFile(t, dir, f, f+"\n")
+	}
+	runHg(t, dir, "commit", "--addremove", "-m", "Initial commit", "-d", "1700000000 0")
+
+	h, err := NewHg(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir, h
+}
+
+// runHg runs the hg command at dir, and returns its trimmed output
+func runHg(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+
+	out, err := execHg(dir, args...)
+	if err != nil {
+		t.Fatalf("hg %v: %v", strings.Join(args, " "), err)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// hgCommitAt commits a change of the given file, dated n hours after the
+// initial commit
+func hgCommitAt(t *testing.T, dir, file string, n int) string {
+	t.Helper()
+
+	writeFile(t, dir, file, fmt.Sprintf("%v %v\n", file, n))
+	runHg(t, dir, "commit", "--addremove", "-m", fmt.Sprintf("Change %v", n), "-d", fmt.Sprintf("%d 0", 1700000000+n*3600), "--", file)
+	return runHg(t, dir, "log", "-r", ".", "-T", "{node}")
+}
+
+func TestHgCommitFiles(t *testing.T) {
+	dir, h := newHgRepo(t, "a.txt", "b.txt")
+	writeFile(t, dir, "a.txt", "a changed\n")
+	writeFile(t, dir, "b.txt", "b changed\n")
+	writeFile(t, dir, "c.txt", "c new\n")
+
+	if err := h.CommitFiles([]string{filepath.Join(dir, "a.txt"), filepath.Join(dir, "c.txt")}, "Bump version"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := runHg(t, dir, "log", "-r", ".", "-T", "{desc}"); got != "Bump version" {
+		t.Errorf("subject = %q, want %q", got, "Bump version")
+	}
+	if got := runHg(t, dir, "log", "-r", ".", "-T", "{files}"); got != "a.txt c.txt" {
+		t.Errorf("committed %q, want a.txt and c.txt", got)
+	}
+	if got := runHg(t, dir, "status", "--no-status"); got != "b.txt" {
+		t.Errorf("modified %q, want b.txt", got)
+	}
+}
+
+func TestHgTags(t *testing.T) {
+	dir, h := newHgRepo(t, "a.txt")
+	initial := runHg(t, dir, "log", "-r", ".", "-T", "{node}")
+
+	if err := h.NewTag("v1.0.0", "Release 1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	bv, err := os.ReadFile(filepath.Join(dir, hgTagsFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.TrimSpace(string(bv)); got != initial+" v1.0.0" {
+		t.Errorf(".hgtags = %q", got)
+	}
+
+	hgCommitAt(t, dir, "a.txt", 1)
+	runHg(t, dir, "tag", "--local", "-r", ".", "wip")
+
+	tags, err := h.Tags()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tags) != 2 {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+
+	if v := tags[0]; v.Name != "v1.0.0" || !v.Annotated || v.Commit != initial || v.Subject != "Release 1.0.0" || v.Tagger != "Tester <tester@example.com>" {
+		t.Errorf("unexpected global tag: %+v", v)
+	}
+	if w := tags[1]; w.Name != "wip" || w.Annotated {
+		t.Errorf("unexpected local tag: %+v", w)
+	}
+}
+
+func TestHgLatestTag(t *testing.T) {
+	dir, h := newHgRepo(t, "a.txt")
+
+	if _, err := h.LatestTag(true); err == nil {
+		t.Error("expected error without tags")
+	}
+
+	hgCommitAt(t, dir, "a.txt", 1)
+	if err := h.NewTag("v1.0.0", "Release 1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	hgCommitAt(t, dir, "a.txt", 2)
+	if err := h.NewTag("v1.1.0", "Release 1.1.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := h.LatestTag(true); err != nil || got != "v1.1.0" {
+		t.Errorf("latest tag = %q (%v), want v1.1.0", got, err)
+	}
+}
+
+func TestHgLog(t *testing.T) {
+	dir, h := newHgRepo(t, "a.txt")
+	if err := h.NewTag("v1.0.0", "Release 1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the tagging changeset is not a commit since the tag
+	list, err := h.Log("v1.0.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 0 {
+		t.Errorf("commits since v1.0.0 = %+v, want none", list)
+	}
+
+	hgCommitAt(t, dir, "a.txt", 1)
+	head := hgCommitAt(t, dir, "b.txt", 2)
+
+	list, err = h.Log("v1.0.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Hash != head || list[0].Subject != "Change 2" || list[1].Subject != "Change 1" {
+		t.Errorf("unexpected commits since v1.0.0: %+v", list)
+	}
+
+	if list, err = h.Log("", ""); err != nil || len(list) != 3 {
+		t.Errorf("unexpected history (%v): %+v", err, list)
+	}
+
+	if c, err := h.Head(); err != nil || c.Hash != head {
+		t.Errorf("HEAD = %+v (%v), want %v", c, err, head)
+	}
+}
+
+func TestHgStatus(t *testing.T) {
+	dir, h := newHgRepo(t, "a.txt", "b.txt", "c.txt")
+	writeFile(t, dir, "a.txt", "a changed\n")
+	writeFile(t, dir, "d.txt", "d new\n")
+	writeFile(t, dir, "e.txt", "e new\n")
+	runHg(t, dir, "add", "d.txt")
+	runHg(t, dir, "remove", "b.txt")
+	if err := os.Remove(filepath.Join(dir, "c.txt")); err != nil {
+		t.Fatal(err)
+	}
+
+	staged, unstaged, untracked, err := h.Status()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := fmt.Sprint(staged, unstaged, untracked)
+	if want := "[d.txt b.txt] [a.txt c.txt] [e.txt]"; got != want {
+		t.Errorf("status = %v, want %v", got, want)
+	}
+}
+
+func TestHgShowAndDiff(t *testing.T) {
+	dir, h := newHgRepo(t, "a.txt", "b.txt")
+	if err := h.NewTag("v1.0.0", "Release 1.0.0"); err != nil {
+		t.Fatal(err)
+	}
+	hgCommitAt(t, dir, "a.txt", 1)
+	hgCommitAt(t, dir, "c.txt", 2)
+
+	if bv, err := h.Show("v1.0.0", "a.txt"); err != nil || string(bv) != "a.txt\n" {
+		t.Errorf("a.txt at v1.0.0 = %q (%v)", bv, err)
+	}
+	if bv, err := h.Show("HEAD~1", "a.txt"); err != nil || string(bv) != "a.txt 1\n" {
+		t.Errorf("a.txt at HEAD~1 = %q (%v)", bv, err)
+	}
+	if _, err := h.Show("HEAD", "missing.txt"); err == nil {
+		t.Error("expected error for a missing file")
+	}
+
+	files, err := h.Diff("v1.0.0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(files, " "); got != "a.txt c.txt" {
+		t.Errorf("changed since v1.0.0 = %q, want a.txt and c.txt", got)
+	}
+}
+
+func TestHgMergeBase(t *testing.T) {
+	dir, h := newHgRepo(t, "a.txt")
+	base := hgCommitAt(t, dir, "a.txt", 1)
+
+	runHg(t, dir, "branch", "feature")
+	hgCommitAt(t, dir, "b.txt", 2)
+
+	runHg(t, dir, "update", "default")
+	hgCommitAt(t, dir, "a.txt", 3)
+
+	if got, err := h.MergeBase("feature", "HEAD"); err != nil || got != base {
+		t.Errorf("merge base = %q (%v), want %v", got, err, base)
+	}
+
+	if branch, err := h.Branch(); err != nil || branch != "default" {
+		t.Errorf("branch = %q (%v), want default", branch, err)
+	}
+}
@@ -0,0 +1,89 @@
+package vcs
+
+import (
+	"errors"
+	"io/fs"
+)
+
+// ErrNoRepository is returned by every operation of None
+var ErrNoRepository = errors.New("Not in a git or Mercurial repository")
+
+// None implements VCS for directories outside any repository, e.g., an
+// unpacked source tarball
+type None struct {
+	// Dir is returned by TopLevel
+	Dir string
+}
+
+var _ VCS = (*None)(nil)
+
+// NewNone returns the None VCS for dir
+func NewNone(dir string) *None {
+	return &None{Dir: dir}
+}
+
+// TopLevel implements the VCS interface
+func (n *None) TopLevel() string {
+	return n.Dir
+}
+
+// LatestTag implements the VCS interface
+func (n *None) LatestTag(noFetch bool) (string, error) {
+	return "", ErrNoRepository
+}
+
+// Tags implements the VCS interface
+func (n *None) Tags() ([]Tag, error) {
+	return nil, ErrNoRepository
+}
+
+// CommitFiles implements the VCS interface
+func (n *None) CommitFiles(files []string, msg string) error {
+	return ErrNoRepository
+}
+
+// NewTag implements the VCS interface
+func (n *None) NewTag(name, msg string) error {
+	return ErrNoRepository
+}
+
+// Log implements the VCS interface
+func (n *None) Log(from, to string) ([]Commit, error) {
+	return nil, ErrNoRepository
+}
+
+// Head implements the VCS interface
+func (n *None) Head() (Commit, error) {
+	return Commit{}, ErrNoRepository
+}
+
+// Branch implements the VCS interface
+func (n *None) Branch() (string, error) {
+	return "", ErrNoRepository
+}
+
+// Status implements the VCS interface
+func (n *None) Status() (staged, unstaged, untracked []string, err error) {
+	err = ErrNoRepository
+	return
+}
+
+// Show implements the VCS interface
+func (n *None) Show(rev, path string) ([]byte, error) {
+	return nil, ErrNoRepository
+}
+
+// TreeFS implements the VCS interface
+func (n *None) TreeFS(rev string) (fs.FS, error) {
+	return nil, ErrNoRepository
+}
+
+// MergeBase implements the VCS interface
+func (n *None) MergeBase(a, b string) (string, error) {
+	return "", ErrNoRepository
+}
+
+// Diff implements the VCS interface
+func (n *None) Diff(from, to string) ([]string, error) {
+	return nil, ErrNoRepository
+}
@@ -0,0 +1,49 @@
+package vcs
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNone(t *testing.T) {
+	dir := t.TempDir()
+	n := NewNone(dir)
+
+	if got := n.TopLevel(); got != dir {
+		t.Errorf("TopLevel() = %q, want %q", got, dir)
+	}
+
+	calls := map[string]func() error{
+		"LatestTag": func() error { _, err := n.LatestTag(true); return err },
+		"Tags":      func() error { _, err := n.Tags(); return err },
+		"CommitFiles": func() error {
+			return n.CommitFiles([]string{"version.json"}, "Bump")
+		},
+		"NewTag":    func() error { return n.NewTag("v1.0.0", "Release") },
+		"Log":       func() error { _, err := n.Log("", ""); return err },
+		"Head":      func() error { _, err := n.Head(); return err },
+		"Branch":    func() error { _, err := n.Branch(); return err },
+		"Status":    func() error { _, _, _, err := n.Status(); return err },
+		"Show":      func() error { _, err := n.Show("HEAD", "version.json"); return err },
+		"TreeFS":    func() error { _, err := n.TreeFS("HEAD"); return err },
+		"MergeBase": func() error { _, err := n.MergeBase("origin/main", "HEAD"); return err },
+		"Diff":      func() error { _, err := n.Diff("HEAD", ""); return err },
+	}
+	for name, call := range calls {
+		if err := call(); !errors.Is(err, ErrNoRepository) {
+			t.Errorf("%v: got %v, want %v", name, err, ErrNoRepository)
+		}
+	}
+}
+
+func TestOpenOutsideRepository(t *testing.T) {
+	dir := t.TempDir()
+
+	v, err := Open(dir, BackendAuto)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := v.(*None); !ok {
+		t.Errorf("Open outside a repository returned %T, want *None", v)
+	}
+}
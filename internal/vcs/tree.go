@@ -0,0 +1,138 @@
+package vcs
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"time"
+)
+
+// treeFS is a read-only view of the tree of a revision
+type treeFS struct {
+	files map[string]int64
+	dirs  map[string][]string
+	read  func(name string) ([]byte, error)
+}
+
+// newTreeFS returns the treeFS for the given file sizes, indexed by path,
+// whose contents are obtained through read
+func newTreeFS(files map[string]int64, read func(name string) ([]byte, error)) *treeFS {
+	t := &treeFS{
+		files: files,
+		dirs:  map[string][]string{".": nil},
+		read:  read,
+	}
+
+	for p := range files {
+		for child := p; child != "."; child = path.Dir(child) {
+			parent := path.Dir(child)
+			t.dirs[parent] = append(t.dirs[parent], path.Base(child))
+		}
+	}
+
+	for d, names := range t.dirs {
+		sort.Strings(names)
+		t.dirs[d] = compactStrings(names)
+	}
+
+	return t
+}
+
+func (t *treeFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+
+	if names, ok := t.dirs[name]; ok {
+		d := &treeDir{info: treeInfo{name: path.Base(name), dir: true}}
+		for _, n := range names {
+			p := path.Join(name, n)
+			_, isDir := t.dirs[p]
+			d.entries = append(d.entries, treeInfo{name: n, size: t.files[p], dir: isDir})
+		}
+		return d, nil
+	}
+
+	if _, ok := t.files[name]; !ok {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	bv, err := t.read(name)
+	if err != nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: err}
+	}
+
+	return &treeFile{
+		Reader: bytes.NewReader(bv),
+		info:   treeInfo{name: path.Base(name), size: int64(len(bv))},
+	}, nil
+}
+
+func compactStrings(list []string) (out []string) {
+	for i, s := range list {
+		if i == 0 || s != list[i-1] {
+			out = append(out, s)
+		}
+	}
+	return
+}
+
+// treeInfo implements both fs.FileInfo and fs.DirEntry
+type treeInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+func (i treeInfo) Name() string { return i.name }
+func (i treeInfo) Size() int64  { return i.size }
+func (i treeInfo) Mode() fs.FileMode {
+	if i.dir {
+		return fs.ModeDir | 0o555
+	}
+	return 0o444
+}
+func (i treeInfo) ModTime() time.Time         { return time.Time{} }
+func (i treeInfo) IsDir() bool                { return i.dir }
+func (i treeInfo) Sys() any                   { return nil }
+func (i treeInfo) Type() fs.FileMode          { return i.Mode().Type() }
+func (i treeInfo) Info() (fs.FileInfo, error) { return i, nil }
+
+type treeFile struct {
+	*bytes.Reader
+	info treeInfo
+}
+
+func (f *treeFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *treeFile) Close() error               { return nil }
+
+type treeDir struct {
+	info    treeInfo
+	entries []treeInfo
+	offset  int
+}
+
+func (d *treeDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *treeDir) Close() error               { return nil }
+
+func (d *treeDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+func (d *treeDir) ReadDir(n int) (list []fs.DirEntry, err error) {
+	rest := d.entries[d.offset:]
+	if n > 0 && len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > 0 && n < len(rest) {
+		rest = rest[:n]
+	}
+
+	for _, e := range rest {
+		list = append(list, e)
+	}
+	d.offset += len(rest)
+	return
+}
@@ -0,0 +1,162 @@
+// Package vcs defines the interface to the version control system of a
+// repository, along with its implementations.
+package vcs
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+)
+
+// Backends
+const (
+	// BackendAuto selects BackendHg for Mercurial repositories and, for git
+	// ones, BackendGit if the git command is available, and BackendGoGit
+	// otherwise
+	BackendAuto = ""
+
+	// BackendGit runs the git command
+	BackendGit = "git"
+
+	// BackendGoGit is implemented in pure Go
+	BackendGoGit = "go-git"
+
+	// BackendHg runs the hg command
+	BackendHg = "hg"
+)
+
+// Backends lists the supported backends
+var Backends = []string{BackendAuto, BackendGit, BackendGoGit, BackendHg}
+
+// VCS is the interface to the version control system of a repository
+type VCS interface {
+	// TopLevel returns the root directory of the working tree
+	TopLevel() string
+
+	// LatestTag returns the most recent tag reachable from any branch,
+	// fetching the remote tags first unless noFetch is set
+	LatestTag(noFetch bool) (string, error)
+
+	// Tags returns every tag
+	Tags() ([]Tag, error)
+
+	// CommitFiles commits the given files, either absolute or relative to the
+	// current directory
+	CommitFiles(files []string, msg string) error
+
+	// NewTag creates an annotated tag at HEAD
+	NewTag(name, msg string) error
+
+	// Log returns the commits reachable from `to` but not from `from`,
+	// newest first. An empty `from` returns the whole history of `to`, and
+	// an empty `to` stands for HEAD
+	Log(from, to string) ([]Commit, error)
+
+	// Head returns the HEAD commit
+	Head() (Commit, error)
+
+	// Branch returns the name of the current branch, or an empty string in
+	// detached HEAD
+	Branch() (string, error)
+
+	// Status returns the staged, unstaged and untracked files
+	Status() (staged, unstaged, untracked []string, err error)
+
+	// Show returns the content of the file at path, relative to the
+	// top-level directory, as of the given revision
+	Show(rev, path string) ([]byte, error)
+
+	// TreeFS returns a read-only fs.FS for the tree of the given revision,
+	// with paths relative to the top-level directory
+	TreeFS(rev string) (fs.FS, error)
+
+	// MergeBase returns the best common ancestor of the given revisions
+	MergeBase(a, b string) (string, error)
+
+	// Diff returns the paths, relative to the top-level directory, of the
+	// files changed between the given revisions. An empty `to` stands for
+	// HEAD
+	Diff(from, to string) ([]string, error)
+}
+
+// Commit describes a single commit
+type Commit struct {
+	Hash    string    `json:"hash"`
+	Time    time.Time `json:"time"`
+	Author  string    `json:"author"`
+	Subject string    `json:"subject"`
+	Body    string    `json:"body,omitempty"`
+}
+
+// Tag describes a single tag
+type Tag struct {
+	Name      string    `json:"name"`
+	Commit    string    `json:"commit"`
+	Annotated bool      `json:"annotated"`
+	Date      time.Time `json:"date"`
+	Tagger    string    `json:"tagger,omitempty"`
+	Subject   string    `json:"subject,omitempty"`
+	Message   string    `json:"message,omitempty"`
+}
+
+// Open returns the VCS of the repository containing dir, using the given
+// backend. If dir is not in a repository, None is returned
+func Open(dir, backend string) (VCS, error) {
+	kind, _ := detect(dir)
+	if kind == "" {
+		return NewNone(dir), nil
+	}
+
+	switch backend {
+	case BackendAuto:
+		if kind == BackendHg {
+			return NewHg(dir)
+		}
+		if _, err := exec.LookPath("git"); err != nil {
+			return NewGoGit(dir)
+		}
+		return NewGit(dir)
+	case BackendGit:
+		return NewGit(dir)
+	case BackendGoGit:
+		return NewGoGit(dir)
+	case BackendHg:
+		return NewHg(dir)
+	}
+	return nil, fmt.Errorf("Unsupported VCS backend: %v", backend)
+}
+
+// Root returns the top-level directory of the repository closest to dir, or
+// an empty string if there is none
+func Root(dir string) string {
+	_, root := detect(dir)
+	return root
+}
+
+// detect returns the kind and top-level directory of the repository closest
+// to dir. The kind is either BackendGit or BackendHg, or an empty string if
+// there is no repository
+func detect(dir string) (kind, root string) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return
+	}
+
+	for {
+		if _, err := os.Stat(filepath.Join(dir, ".hg")); err == nil {
+			return BackendHg, dir
+		}
+		if _, err := os.Stat(filepath.Join(dir, ".git")); err == nil {
+			return BackendGit, dir
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return
+		}
+		dir = parent
+	}
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	_ "embed"
+	"errors"
 	"fmt"
 	"log/slog"
 	"os"
@@ -15,7 +16,7 @@ import (
 //go:embed version.json
 var versionJSON []byte
 
-var appVersion version.Version
+var appInfo version.Info
 
 var logger *slog.Logger
 
@@ -25,7 +26,7 @@ func main() {
 
 	app := &cli.Command{
 		Name:      "bumpy-ride",
-		Version:   appVersion.String(),
+		Version:   appInfo.String(),
 		Copyright: "(c) 2022 WalrusAhead Solutions",
 		Usage:     "A versioning tool",
 		UsageText: "bumpy [command] [options ...]",
@@ -34,21 +35,40 @@ func main() {
 				fmt.Fprintf(c.ErrWriter, err.Error()+"\n")
 			}
 		},
+		Before: task.Before,
+		Flags: []cli.Flag{
+			task.OutputFlag(),
+			task.DirFlag(),
+		},
 		Commands: []*cli.Command{
 			task.Init(),
 			task.Bump(),
 			task.Sync(),
 			task.Tag(),
 			task.Version(),
+			task.Next(),
+			task.Status(),
+			task.History(),
+			task.Show(),
+			task.Audit(),
+			task.Check(),
+			task.LDFlags(),
 			task.Config(),
 		},
 	}
 
-	app.Run(context.Background(), os.Args)
+	if err := app.Run(context.Background(), os.Args); err != nil {
+		var ec cli.ExitCoder
+		if errors.As(err, &ec) {
+			os.Exit(ec.ExitCode())
+		}
+		os.Exit(1)
+	}
 }
 
 func init() {
-	if err := appVersion.Read(versionJSON); err != nil {
+	var err error
+	if appInfo, err = version.NewInfo(versionJSON); err != nil {
 		panic(err)
 	}
 }
@@ -0,0 +1,357 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/conventional"
+	"github.com/jwmwalrus/bumpy/internal/generate"
+	"github.com/jwmwalrus/bumpy/internal/updater"
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+// Increment defines how the version is increased
+type Increment int
+
+// Increments
+const (
+	// Custom sets the version to BumpOptions.Version, if given, and leaves
+	// it unchanged otherwise
+	Custom Increment = iota
+	Patch
+	Minor
+	Major
+
+	// Auto increases the version as required by the Conventional Commits
+	// since the latest tag, defaulting to a patch increment
+	Auto
+)
+
+func (i Increment) String() string {
+	switch i {
+	case Patch:
+		return "patch"
+	case Minor:
+		return "minor"
+	case Major:
+		return "major"
+	case Auto:
+		return "auto"
+	}
+	return "custom"
+}
+
+// BumpOptions defines the options of Bump and Next
+type BumpOptions struct {
+	// Dir is the directory to work on. Defaults to the current one
+	Dir string
+
+	Increment Increment
+
+	// Version is the version to set with the Custom increment
+	Version string
+
+	// Pre and Build, if given, replace the prerelease and build strings
+	Pre   string
+	Build string
+
+	// NoCommit skips committing the changed files, regardless of the
+	// `noCommit` setting, as the `--no-commit` flag layer of the config
+	NoCommit bool
+
+	// Log receives human-readable progress messages
+	Log io.Writer
+}
+
+// BumpResult is the outcome of Bump
+type BumpResult struct {
+	OldVersion version.Version
+	NewVersion version.Version
+
+	// Files lists the files written
+	Files []string
+
+	// Commit is the hash of the commit created, if any
+	Commit string
+
+	// Warnings lists the non-fatal issues found
+	Warnings []string
+}
+
+// Next returns the current version and the one Bump would produce for the
+// given options, without changing anything
+func Next(ctx context.Context, opts BumpOptions) (current, next version.Version, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	cfg, err := load(opts.Dir)
+	if err != nil {
+		return
+	}
+
+	if err = current.LoadFrom(cfg.Path(cfg.VersionPrefix)); err != nil {
+		return
+	}
+
+	next, err = nextVersion(cfg, current, opts, newLogger(nil))
+	return
+}
+
+// Bump increases the version, updates the version file and every configured
+// updater and generated file, and commits them unless disabled
+func Bump(ctx context.Context, opts BumpOptions) (res BumpResult, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	log := newLogger(opts.Log)
+
+	var flags []config.Flag
+	if opts.NoCommit {
+		flags = append(flags, config.Flag{Name: "--no-commit", Key: "noCommit", Value: true})
+	}
+
+	cfg, err := load(opts.Dir, flags...)
+	if err != nil {
+		return
+	}
+
+	commit := !cfg.NoCommit
+	if commit && !cfg.HasVCS() {
+		err = fmt.Errorf("%w, unable to commit (consider using `noCommit`)", ErrNoRepository)
+		return
+	}
+
+	var v version.Version
+	if err = v.LoadFrom(cfg.Path(cfg.VersionPrefix)); err != nil {
+		return
+	}
+
+	if !cfg.HasVCS() {
+		log.Printf("\nNot in a repository, updating files only...\n")
+	} else if _, tagErr := cfg.VCS.LatestTag(cfg.NoFetch); tagErr != nil {
+		res.Warnings = append(res.Warnings, "unable to obtain latest tag: "+tagErr.Error())
+	}
+
+	res.OldVersion = v
+
+	if v, err = nextVersion(cfg, v, opts, log); err != nil {
+		return
+	}
+
+	if err = v.SaveTo(cfg.Path(cfg.VersionPrefix)); err != nil {
+		return
+	}
+
+	slist := []string{
+		filepath.Join(".", cfg.File),
+		filepath.Join(cfg.VersionPrefix, version.Filename),
+	}
+
+	for _, p := range cfg.NPMPrefixes {
+		var jsonFiles []string
+		if jsonFiles, err = updatePackageJSON(cfg, p, v); err != nil {
+			return
+		}
+
+		for _, f := range jsonFiles {
+			slist = append(slist, f)
+		}
+	}
+
+	for _, p := range cfg.CargoPrefixes {
+		log.Printf("\nUpdating Cargo manifests at `%v`...\n", p)
+
+		var cargoFiles []string
+		if cargoFiles, err = updater.UpdateCargo(cfg.Path(p), v); err != nil {
+			return
+		}
+
+		slist = append(slist, relPaths(cfg, cargoFiles)...)
+	}
+
+	for _, p := range cfg.MavenPrefixes {
+		log.Printf("\nUpdating Maven POM files at `%v`...\n", p)
+
+		var mavenFiles []string
+		if mavenFiles, err = updater.UpdateMaven(cfg.Path(p), v); err != nil {
+			return
+		}
+
+		slist = append(slist, relPaths(cfg, mavenFiles)...)
+	}
+
+	for _, p := range cfg.GradlePrefixes {
+		log.Printf("\nUpdating Gradle properties at `%v`...\n", p)
+
+		var gradleFiles []string
+		if gradleFiles, err = updater.UpdateGradle(cfg.Path(p), v); err != nil {
+			return
+		}
+
+		slist = append(slist, relPaths(cfg, gradleFiles)...)
+	}
+
+	if len(cfg.Generate) > 0 {
+		log.Printf("\nGenerating version source files...\n")
+
+		var generated []string
+		if generated, err = generate.Write(cfg.Dir, cfg.Generate, v); err != nil {
+			return
+		}
+
+		slist = append(slist, generated...)
+	}
+
+	if commit {
+		log.Printf("\nCommitting files...\n")
+
+		if err = cfg.VCS.CommitFiles(absPaths(cfg, slist), "Bump version"); err != nil {
+			return
+		}
+
+		if res.Commit, err = headCommit(cfg); err != nil {
+			return
+		}
+	}
+
+	res.NewVersion = v
+	res.Files = slist
+
+	log.Printf("Done!\n\nNext tag will be: %v\n", v.String())
+	return
+}
+
+// nextVersion applies the given options to the version
+func nextVersion(cfg *config.Config, v version.Version, opts BumpOptions, log logger) (version.Version, error) {
+	if opts.Increment != Custom && opts.Version != "" {
+		return v, ErrTooManyOptions
+	}
+
+	old := v
+
+	switch opts.Increment {
+	case Major:
+		log.Printf("\nBumping `major`...\n")
+		v = v.NextMajor()
+	case Minor:
+		log.Printf("\nBumping `minor`...\n")
+		v = v.NextMinor()
+	case Patch:
+		log.Printf("\nBumping `patch`...\n")
+		v = v.NextPatch()
+	case Auto:
+		level, err := requiredLevel(cfg)
+		if err != nil {
+			return v, err
+		}
+
+		log.Printf("\nBumping `%v`, as required by the commits since the latest tag...\n", level)
+		switch level {
+		case conventional.Major:
+			v = v.NextMajor()
+		case conventional.Minor:
+			v = v.NextMinor()
+		default:
+			v = v.NextPatch()
+		}
+	default:
+		if opts.Version != "" {
+			log.Printf("\nBumping to custom version: %s...\n", opts.Version)
+			if err := v.Parse(opts.Version); err != nil {
+				return v, err
+			}
+		}
+	}
+
+	if err := checkIncrement(cfg, old, v); err != nil {
+		return v, err
+	}
+
+	if opts.Pre != "" {
+		log.Printf("\nAdding `pre`: %s...\n", opts.Pre)
+		v.Pre = opts.Pre
+	} else if pre := cfg.ProfilePre(); pre != "" && (opts.Increment != Custom || opts.Version != "") {
+		log.Printf("\nAdding `pre`, as set by the `%v` profile: %s...\n", cfg.Profile.Branch, pre)
+		v.Pre = pre
+	}
+	if opts.Build != "" {
+		log.Printf("\nAdding `build`: %s...\n", opts.Build)
+		v.Build = opts.Build
+	}
+
+	return v, nil
+}
+
+// checkIncrement verifies that the increment from old to v is allowed by the
+// profile of the current branch, if any
+func checkIncrement(cfg *config.Config, old, v version.Version) error {
+	if cfg.Profile == nil || len(cfg.Profile.Increments) == 0 {
+		return nil
+	}
+
+	var inc string
+	switch {
+	case v.Major != old.Major:
+		inc = config.IncrementMajor
+	case v.Minor != old.Minor:
+		inc = config.IncrementMinor
+	case v.Patch != old.Patch:
+		inc = config.IncrementPatch
+	default:
+		return nil
+	}
+
+	if !slices.Contains(cfg.Profile.Increments, inc) {
+		return fmt.Errorf("%w: `%v` on branch `%v` (`%v` profile allows %v)", ErrNotAllowed, inc, cfg.Branch, cfg.Profile.Branch, strings.Join(cfg.Profile.Increments, ", "))
+	}
+	return nil
+}
+
+// requiredLevel returns the increment required by the Conventional Commits
+// since the latest tag, defaulting to a patch increment
+func requiredLevel(cfg *config.Config) (level conventional.Level, err error) {
+	tag, err := cfg.VCS.LatestTag(cfg.NoFetch)
+	if err != nil {
+		tag = ""
+	}
+
+	commits, err := cfg.VCS.Log(tag, "")
+	if err != nil {
+		return
+	}
+
+	for _, cm := range commits {
+		level = conventional.Max(level, conventional.LevelOf(cm.Subject, cm.Body))
+	}
+
+	if level == conventional.None {
+		level = conventional.Patch
+	}
+	return
+}
+
+func headCommit(cfg *config.Config) (hash string, err error) {
+	c, err := cfg.VCS.Head()
+	if err != nil {
+		return
+	}
+	hash = c.Hash
+	return
+}
+
+func updatePackageJSON(cfg *config.Config, prefix string, v version.Version) (files []string, err error) {
+	if _, err = exec.Command("npm", "version", "--prefix", cfg.Path(prefix), "--no-git-tag-version", v.String()).CombinedOutput(); err != nil {
+		return
+	}
+	files = append(files, filepath.Join(prefix, "package.json"))
+	files = append(files, filepath.Join(prefix, "package-lock.json"))
+	return
+}
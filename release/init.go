@@ -0,0 +1,168 @@
+package release
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+// InitOptions defines the options of Init. Non-zero settings are persisted
+// in the config file
+type InitOptions struct {
+	// Dir is the directory to work on. Defaults to the current one
+	Dir string
+
+	// Persist commits the created files
+	Persist bool
+
+	NoFetch        bool
+	NoCommit       bool
+	VersionPrefix  string
+	NPMPrefixes    []string
+	CargoPrefixes  []string
+	MavenPrefixes  []string
+	GradlePrefixes []string
+
+	// Log receives human-readable progress messages
+	Log io.Writer
+}
+
+// InitResult is the outcome of Init
+type InitResult struct {
+	Version       version.Version
+	ConfigCreated bool
+
+	// Files lists the files written
+	Files []string
+
+	// Commit is the hash of the commit created, if any
+	Commit string
+}
+
+// Init creates the config file, if missing, and the version file, set to
+// the latest tag or to the initial version
+func Init(ctx context.Context, opts InitOptions) (res InitResult, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	log := newLogger(opts.Log)
+
+	dir, err := findDir(opts.Dir)
+	if err != nil {
+		return
+	}
+
+	cfg, configCreated, err := config.LoadOrCreateFrom(dir)
+	if err != nil {
+		return
+	}
+
+	if opts.Persist && !cfg.HasVCS() {
+		err = fmt.Errorf("%w, unable to persist", ErrNoRepository)
+		return
+	}
+
+	res.ConfigCreated = configCreated
+
+	if !configCreated {
+		log.Printf("Config file already existed!\n")
+	}
+
+	versionFile := filepath.Join(cfg.VersionPrefix, version.Filename)
+	_, err = os.Stat(cfg.Path(versionFile))
+	if !os.IsNotExist(err) {
+		if !configCreated {
+			err = ErrAlreadyInitialized
+			return
+		}
+	}
+
+	if opts.NoFetch {
+		if !configCreated {
+			log.Printf("Overriding `noFetch` in config file")
+		}
+		cfg.NoFetch = opts.NoFetch
+	}
+	if opts.NoCommit {
+		if !configCreated {
+			log.Printf("Overriding `noCommit` in config file")
+		}
+		cfg.NoCommit = opts.NoCommit
+	}
+	if opts.VersionPrefix != "" {
+		if !configCreated {
+			log.Printf("Overriding `prefix` in config file")
+		}
+		cfg.VersionPrefix = opts.VersionPrefix
+	}
+	if len(opts.NPMPrefixes) > 0 {
+		if !configCreated {
+			log.Printf("Overriding `npmPrefix` in config file")
+		}
+		cfg.NPMPrefixes = opts.NPMPrefixes
+	}
+	if len(opts.CargoPrefixes) > 0 {
+		if !configCreated {
+			log.Printf("Overriding `cargoPrefixes` in config file")
+		}
+		cfg.CargoPrefixes = opts.CargoPrefixes
+	}
+	if len(opts.MavenPrefixes) > 0 {
+		if !configCreated {
+			log.Printf("Overriding `mavenPrefixes` in config file")
+		}
+		cfg.MavenPrefixes = opts.MavenPrefixes
+	}
+	if len(opts.GradlePrefixes) > 0 {
+		if !configCreated {
+			log.Printf("Overriding `gradlePrefixes` in config file")
+		}
+		cfg.GradlePrefixes = opts.GradlePrefixes
+	}
+
+	if err = cfg.Save(); err != nil {
+		return
+	}
+
+	v := version.Version{}
+	tag, err := cfg.VCS.LatestTag(cfg.NoFetch)
+	if err != nil {
+		v = version.New()
+	} else {
+		if err = v.Parse(tag); err != nil {
+			return
+		}
+	}
+
+	if err = v.SaveTo(cfg.Path(cfg.VersionPrefix)); err != nil {
+		return
+	}
+
+	res.Files = []string{
+		filepath.Join(".", cfg.File),
+		versionFile,
+	}
+
+	if opts.Persist {
+		log.Printf("\nCommitting files...\n")
+		err = cfg.VCS.CommitFiles(absPaths(cfg, res.Files), "Init version")
+		if err != nil {
+			return
+		}
+
+		if res.Commit, err = headCommit(cfg); err != nil {
+			return
+		}
+	}
+
+	res.Version = v
+
+	log.Printf("Done!\n")
+	return
+}
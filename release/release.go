@@ -0,0 +1,97 @@
+// Package release exposes the bumpy operations (init, bump, tag and sync)
+// as a Go API, for use by release tools.
+//
+// Every operation works on the directory given by the Dir field of its
+// options, defaulting to the current one, and reads the closest
+// `.bumpy-ride` config file, looking upwards up to the top-level directory of
+// the repository. Every path, including the ones in the results, is relative
+// to the directory of said file, regardless of the current directory, so
+// operations on different directories can run concurrently. Human-readable
+// progress is written to the Log writer of the options, if any, and the
+// outcome is returned as a structured result.
+package release
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+)
+
+var (
+	// ErrAlreadyInitialized is returned by Init when both the config and the
+	// version file exist
+	ErrAlreadyInitialized = errors.New("Repository is already initialized, isn't it?")
+
+	// ErrTooManyOptions is returned by Bump and Next when both an increment
+	// and a custom version are requested
+	ErrTooManyOptions = errors.New("Too many options provided")
+
+	// ErrNotAllowed is returned by Bump and Next when the increment is not
+	// allowed by the profile of the current branch
+	ErrNotAllowed = errors.New("Increment not allowed by the branch profile")
+
+	// ErrNoRepository is returned by the operations that commit or tag,
+	// when run outside of a repository
+	ErrNoRepository = vcs.ErrNoRepository
+)
+
+// logger writes progress messages to an optional writer
+type logger struct {
+	w io.Writer
+}
+
+func newLogger(w io.Writer) logger {
+	if w == nil {
+		w = io.Discard
+	}
+	return logger{w}
+}
+
+func (l logger) Printf(format string, a ...any) {
+	fmt.Fprintf(l.w, format, a...)
+}
+
+// findDir returns the directory of the config file closest to the given one,
+// or to the current directory if empty
+func findDir(dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	return config.Find(dir)
+}
+
+// load loads the configuration closest to the given directory, with the
+// given flags as its last layer
+func load(dir string, flags ...config.Flag) (cfg *config.Config, err error) {
+	if dir, err = findDir(dir); err != nil {
+		return
+	}
+	return config.LoadFrom(dir, flags...)
+}
+
+// absPaths returns the given paths, relative to the directory of the
+// configuration, as absolute ones
+func absPaths(cfg *config.Config, files []string) []string {
+	list := make([]string, 0, len(files))
+	for _, f := range files {
+		list = append(list, cfg.Path(f))
+	}
+	return list
+}
+
+// relPaths returns the given absolute paths relative to the directory of the
+// configuration
+func relPaths(cfg *config.Config, files []string) []string {
+	list := make([]string, 0, len(files))
+	for _, f := range files {
+		if rel, err := filepath.Rel(cfg.Dir, f); err == nil {
+			f = rel
+		}
+		list = append(list, f)
+	}
+	return list
+}
@@ -0,0 +1,209 @@
+package release
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+)
+
+// newRepo returns the top-level directory of a new repository, marked as such
+// for the config discovery, isolated from the user-level config and the
+// BUMPY_* environment variables
+func newRepo(t *testing.T) string {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Mkdir(filepath.Join(dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("GITHUB_HEAD_REF", "")
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, config.EnvPrefix) {
+			t.Setenv(name, "")
+		}
+	}
+	return dir
+}
+
+// useVCS injects the given VCS through config.OpenVCS
+func useVCS(t *testing.T, v vcs.VCS) {
+	open := config.OpenVCS
+	config.OpenVCS = func(string, string) (vcs.VCS, error) { return v, nil }
+	t.Cleanup(func() { config.OpenVCS = open })
+}
+
+// newFake returns a new repository backed by a vcs.Fake on the main branch
+func newFake(t *testing.T) (string, *vcs.Fake) {
+	t.Helper()
+
+	dir := newRepo(t)
+	fake := vcs.NewFake(dir)
+	fake.CurrentBranch = "main"
+
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	fake.Now = func() time.Time {
+		clock = clock.Add(time.Hour)
+		return clock
+	}
+
+	useVCS(t, fake)
+	return dir, fake
+}
+
+func TestRelease(t *testing.T) {
+	dir, fake := newFake(t)
+	ctx := context.Background()
+
+	ires, err := Init(ctx, InitOptions{Dir: dir, Persist: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ires.ConfigCreated || ires.Version.String() != "v0.1.0" || ires.Commit != fake.Commits[0].Hash {
+		t.Errorf("unexpected init result: %+v", ires)
+	}
+	if _, err = Init(ctx, InitOptions{Dir: dir}); !errors.Is(err, ErrAlreadyInitialized) {
+		t.Errorf("second init: got %v, want %v", err, ErrAlreadyInitialized)
+	}
+
+	// the latest tag is missing, which is only a warning
+	bres, err := Bump(ctx, BumpOptions{Dir: dir, Increment: Minor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bres.NewVersion.String() != "v0.2.0" || bres.Commit != fake.Commits[1].Hash || fake.Commits[1].Subject != "Bump version" {
+		t.Errorf("unexpected bump result: %+v", bres)
+	}
+	if len(bres.Warnings) != 1 || !strings.Contains(bres.Warnings[0], "unable to obtain latest tag") {
+		t.Errorf("warnings = %v", bres.Warnings)
+	}
+
+	tres, err := Tag(ctx, TagOptions{Dir: dir, Message: "Release 0.2.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tres.Tag != "v0.2.0" || len(fake.TagList) != 1 || fake.TagList[0].Message != "Release 0.2.0" {
+		t.Errorf("unexpected tag result: %+v, tags %+v", tres, fake.TagList)
+	}
+
+	if bres, err = Bump(ctx, BumpOptions{Dir: dir, Increment: Patch, NoCommit: true}); err != nil {
+		t.Fatal(err)
+	}
+	if bres.NewVersion.String() != "v0.2.1" || bres.Commit != "" || len(fake.Commits) != 2 || len(bres.Warnings) != 0 {
+		t.Errorf("unexpected bump result: %+v", bres)
+	}
+
+	sres, err := Sync(ctx, SyncOptions{Dir: dir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sres.OldVersion == nil || sres.OldVersion.String() != "v0.2.1" || sres.NewVersion.String() != "v0.2.0" {
+		t.Errorf("unexpected sync result: %+v", sres)
+	}
+}
+
+func TestDir(t *testing.T) {
+	dir, _ := newFake(t)
+	ctx := context.Background()
+
+	sub := filepath.Join(dir, "cmd", "app")
+	if err := os.MkdirAll(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// initialized at the top-level directory, and found from below it
+	if _, err = Init(ctx, InitOptions{Dir: sub}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, config.Filename)); err != nil {
+		t.Errorf("config not created at the top-level directory: %v", err)
+	}
+
+	current, next, err := Next(ctx, BumpOptions{Dir: sub, Increment: Major})
+	if err != nil || current.String() != "v0.1.0" || next.String() != "v1.0.0" {
+		t.Errorf("next = %v -> %v (%v), want v0.1.0 -> v1.0.0", current, next, err)
+	}
+
+	res, err := Bump(ctx, BumpOptions{Dir: sub, Increment: Major})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Contains(res.Files, "version.json") {
+		t.Errorf("files %v are not relative to the config directory", res.Files)
+	}
+
+	if got, _ := os.Getwd(); got != wd {
+		t.Errorf("working directory changed to %v", got)
+	}
+
+	other := t.TempDir()
+	if _, _, err = Next(ctx, BumpOptions{Dir: other}); !errors.Is(err, config.ErrNotFound) {
+		t.Errorf("next at %v: got %v, want %v", other, err, config.ErrNotFound)
+	}
+}
+
+func TestNoRepository(t *testing.T) {
+	dir := newRepo(t)
+	useVCS(t, vcs.NewNone(dir))
+	ctx := context.Background()
+
+	if _, err := Init(ctx, InitOptions{Dir: dir, Persist: true}); !errors.Is(err, ErrNoRepository) {
+		t.Errorf("init --persist: got %v, want %v", err, ErrNoRepository)
+	}
+
+	res, err := Init(ctx, InitOptions{Dir: dir})
+	if err != nil || res.Version.String() != "v0.1.0" {
+		t.Fatalf("init = %+v (%v)", res, err)
+	}
+
+	if _, err = Bump(ctx, BumpOptions{Dir: dir, Increment: Patch}); !errors.Is(err, ErrNoRepository) {
+		t.Errorf("bump: got %v, want %v", err, ErrNoRepository)
+	}
+
+	bres, err := Bump(ctx, BumpOptions{Dir: dir, Increment: Patch, NoCommit: true})
+	if err != nil || bres.NewVersion.String() != "v0.1.1" {
+		t.Errorf("bump --no-commit = %+v (%v)", bres, err)
+	}
+
+	if _, err = Tag(ctx, TagOptions{Dir: dir, Message: "x"}); !errors.Is(err, ErrNoRepository) {
+		t.Errorf("tag: got %v, want %v", err, ErrNoRepository)
+	}
+	if _, err = Sync(ctx, SyncOptions{Dir: dir}); !errors.Is(err, ErrNoRepository) {
+		t.Errorf("sync: got %v, want %v", err, ErrNoRepository)
+	}
+}
+
+func TestCanceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := Init(ctx, InitOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("init: got %v", err)
+	}
+	if _, err := Bump(ctx, BumpOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("bump: got %v", err)
+	}
+	if _, err := Tag(ctx, TagOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("tag: got %v", err)
+	}
+	if _, err := Sync(ctx, SyncOptions{}); !errors.Is(err, context.Canceled) {
+		t.Errorf("sync: got %v", err)
+	}
+}
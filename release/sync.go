@@ -0,0 +1,69 @@
+package release
+
+import (
+	"context"
+	"io"
+	"path/filepath"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+// SyncOptions defines the options of Sync
+type SyncOptions struct {
+	// Dir is the directory to work on. Defaults to the current one
+	Dir string
+
+	// Log receives human-readable progress messages
+	Log io.Writer
+}
+
+// SyncResult is the outcome of Sync
+type SyncResult struct {
+	// OldVersion is nil if the version file could not be read
+	OldVersion *version.Version
+	NewVersion version.Version
+	Tag        string
+
+	// Files lists the files written
+	Files []string
+}
+
+// Sync sets the version file to the latest tag
+func Sync(ctx context.Context, opts SyncOptions) (res SyncResult, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	log := newLogger(opts.Log)
+
+	cfg, err := load(opts.Dir)
+	if err != nil {
+		return
+	}
+
+	var old version.Version
+	if err = old.LoadFrom(cfg.Path(cfg.VersionPrefix)); err == nil {
+		res.OldVersion = &old
+	}
+
+	tag := ""
+	if tag, err = cfg.VCS.LatestTag(cfg.NoFetch); err != nil {
+		return
+	}
+
+	v := version.Version{}
+	if err = v.Parse(tag); err != nil {
+		return
+	}
+
+	if err = v.SaveTo(cfg.Path(cfg.VersionPrefix)); err != nil {
+		return
+	}
+
+	res.NewVersion = v
+	res.Tag = tag
+	res.Files = []string{filepath.Join(cfg.VersionPrefix, version.Filename)}
+
+	log.Printf("Done!\n")
+	return
+}
@@ -0,0 +1,111 @@
+package release
+
+import (
+	"context"
+	"io"
+	"strings"
+
+	"github.com/jwmwalrus/bumpy/internal/changelog"
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+// TagOptions defines the options of Tag
+type TagOptions struct {
+	// Dir is the directory to work on. Defaults to the current one
+	Dir string
+
+	// ChangeLog is the name of the ChangeLog file, relative to the directory
+	// of the config file unless absolute. If empty, the usual names are
+	// looked up
+	ChangeLog string
+
+	// Message is the tag message to use instead of parsing the ChangeLog
+	Message string
+
+	// Log receives human-readable progress messages
+	Log io.Writer
+}
+
+// TagResult is the outcome of Tag
+type TagResult struct {
+	Version version.Version
+	Tag     string
+	Message string
+
+	// Files lists the files committed
+	Files []string
+
+	// Commit is the hash of the ChangeLog commit, if any
+	Commit string
+}
+
+// Tag commits the ChangeLog and creates an annotated tag for the current
+// version, using the matching ChangeLog entry as message
+func Tag(ctx context.Context, opts TagOptions) (res TagResult, err error) {
+	if err = ctx.Err(); err != nil {
+		return
+	}
+
+	log := newLogger(opts.Log)
+
+	cfg, err := load(opts.Dir)
+	if err != nil {
+		return
+	}
+
+	if !cfg.HasVCS() {
+		err = ErrNoRepository
+		return
+	}
+
+	log.Printf("\nLoading current version file...\n")
+	v := version.Version{}
+	if err = v.LoadFrom(cfg.Path(cfg.VersionPrefix)); err != nil {
+		return
+	}
+
+	log.Printf("\tVersion to use as tag: %v\n", v.String())
+
+	msg := opts.Message
+	if msg == "" {
+		filename := opts.ChangeLog
+
+		if filename, err = changelog.Resolve(log.w, cfg.Dir, filename); err != nil {
+			return
+		}
+
+		log.Printf("\nLoading %v...\n", filename)
+
+		msg = changelog.Message(log.w, v, cfg.Path(filename))
+		msg = strings.TrimSuffix(msg, "\n")
+
+		log.Printf("\nCommitting ChangeLog file...\n")
+		err = cfg.VCS.CommitFiles([]string{cfg.Path(filename)}, "Update ChangeLog")
+		if err != nil {
+			return
+		}
+
+		if res.Commit, err = headCommit(cfg); err != nil {
+			return
+		}
+		res.Files = append(res.Files, relPaths(cfg, []string{cfg.Path(filename)})...)
+	}
+
+	if msg == "" {
+		msg = "New version"
+	}
+
+	log.Printf("\nCreating annotated tag with `%s` as message\n", msg)
+	err = cfg.VCS.NewTag(v.String(), msg)
+	if err != nil {
+		return
+	}
+
+	res.Version = v
+	res.Tag = v.String()
+	res.Message = msg
+
+	log.Printf("\nDone!\n")
+
+	return
+}
@@ -0,0 +1,170 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+// auditFinding is a single item of the audit report
+type auditFinding struct {
+	Tag     string `json:"tag"`
+	Check   string `json:"check"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Audit checks the consistency of every version tag.
+func Audit() *cli.Command {
+	return &cli.Command{
+		Name:            "audit",
+		Category:        "Informational",
+		Usage:           "Audit version tags",
+		UsageText:       "audit [--component NAME] [--all]",
+		Description:     "Walks every version tag, reading the version file and the configured updater files at the tagged commit, and reports mismatches, non-SemVer tags, lightweight tags and duplicate versions. Exits with a non-zero status if a mismatch is found",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          auditAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "component",
+				Usage: "Audit only tags of the given `COMPONENT`, named like 'COMPONENT/v1.2.3'",
+			},
+			&cli.BoolFlag{
+				Name:  "all",
+				Usage: "Report consistent tags too",
+			},
+		},
+	}
+}
+
+func auditAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+
+	tags, err := cfg.VCS.Tags()
+	if err != nil {
+		return
+	}
+
+	component := c.String("component")
+	var list []versionTag
+	for _, t := range tags {
+		comp, name := splitComponent(t.Name)
+		if comp != component {
+			continue
+		}
+
+		vt := versionTag{Tag: t, Component: comp}
+		if pErr := vt.Version.Parse(name); pErr != nil {
+			o.audit(t.Name, "semver", checkWarn, "not a valid SemVer version")
+			continue
+		}
+		list = append(list, vt)
+	}
+
+	seen := map[string]string{}
+	for _, t := range list {
+		key := t.Version.StringNoV()
+		if t.Version.Build != "" {
+			key = strings.TrimSuffix(key, "+"+t.Version.Build)
+		}
+
+		if other, ok := seen[key]; ok {
+			o.audit(t.Name, "duplicate", checkWarn, "same version as %v", other)
+		} else {
+			seen[key] = t.Name
+		}
+
+		if !t.Annotated {
+			o.audit(t.Name, "annotated", checkWarn, "lightweight tag")
+		}
+
+		n := len(o.res.Audit)
+		auditTag(o, cfg, t)
+		if c.Bool("all") && n == len(o.res.Audit) {
+			o.audit(t.Name, "tag", checkOK, "consistent, at %v", shortHash(t.Commit))
+		}
+	}
+
+	if len(o.res.Audit) > 0 {
+		var sb strings.Builder
+		w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+		for _, f := range o.res.Audit {
+			w.Write([]byte("[" + f.Status + "]\t" + f.Tag + "\t" + f.Check + "\t" + f.Message + "\n"))
+		}
+		w.Flush()
+		o.Println(strings.TrimSuffix(sb.String(), "\n"))
+	}
+	o.Printf("\nAudited %v tag(s)\n", len(list))
+
+	for _, f := range o.res.Audit {
+		if f.Status == checkFail {
+			err = cli.Exit("Version tags are inconsistent", 1)
+			break
+		}
+	}
+	return
+}
+
+// auditTag checks the version file and the updater files at the given tag
+func auditTag(o *output, cfg *config.Config, t versionTag) {
+	fsys, err := cfg.VCS.TreeFS(t.Name)
+	if err != nil {
+		o.audit(t.Name, "tree", checkFail, "unable to read tree: %v", err)
+		return
+	}
+
+	versionFile := cfg.RepoPath(filepath.Join(cfg.VersionPrefix, version.Filename))
+	bv, err := fs.ReadFile(fsys, versionFile)
+	if errors.Is(err, fs.ErrNotExist) {
+		o.audit(t.Name, "version", checkWarn, "no %v at tagged commit", versionFile)
+	} else if err != nil {
+		o.audit(t.Name, "version", checkFail, "%v: %v", versionFile, err)
+	} else {
+		var v version.Version
+		if err = v.Read(bv); err != nil {
+			o.audit(t.Name, "version", checkFail, "%v: %v", versionFile, err)
+		} else if !v.Equals(t.Version) {
+			o.audit(t.Name, "version", checkFail, "%v has %v", versionFile, v.String())
+		}
+	}
+
+	for _, r := range updaterReaders(cfg, t.Version) {
+		for _, p := range r.prefixes {
+			list, err := r.read(fsys, cfg.RepoPath(p))
+			if errors.Is(err, fs.ErrNotExist) {
+				o.audit(t.Name, r.name, checkWarn, "%v not present at tagged commit", p)
+				continue
+			} else if err != nil {
+				o.audit(t.Name, r.name, checkFail, "%v: %v", p, err)
+				continue
+			}
+
+			for _, fv := range list {
+				if fv.Version == r.expected {
+					continue
+				}
+
+				file := fv.Path
+				if fv.Name != "" {
+					file += " (" + fv.Name + ")"
+				}
+				o.audit(t.Name, r.name, checkFail, "%v has %v, expected %v", file, fv.Version, r.expected)
+			}
+		}
+	}
+}
@@ -2,13 +2,8 @@ package task
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"os/exec"
-	"path/filepath"
 
-	"github.com/jwmwalrus/bumpy/internal/config"
-	"github.com/jwmwalrus/bumpy/version"
+	"github.com/jwmwalrus/bumpy/release"
 	"github.com/urfave/cli/v3"
 )
 
@@ -19,164 +14,97 @@ func Bump() *cli.Command {
 		Aliases:         []string{"b"},
 		Category:        "Git",
 		Usage:           "Increase current version",
-		UsageText:       "bump [--major|--minor|--patch] [--pre PRE] [--build BUILD] ...",
-		Description:     "Increases the current version according to the given options",
+		UsageText:       "bump [--major|--minor|--patch|--auto] [--pre PRE] [--build BUILD] [--no-commit] ...",
+		Description:     "Increases the current version according to the given options. Outside of a repository, the '--no-commit' flag is required",
 		SkipFlagParsing: false,
 		HideHelp:        false,
 		Hidden:          false,
-		Before:          checkVersionInSync,
 		Action:          bumpAction,
-		Flags: []cli.Flag{
-			&cli.BoolFlag{
-				Name:    "major",
-				Aliases: []string{"maj"},
-				Usage:   "Increase major version number",
-			},
-			&cli.BoolFlag{
-				Name:    "minor",
-				Aliases: []string{"min"},
-				Usage:   "Increase minor version number",
-			},
-			&cli.BoolFlag{
-				Name:    "patch",
-				Aliases: []string{"p"},
-				Usage:   "Increase patch version number",
-			},
-			&cli.StringFlag{
-				Name:  "pre",
-				Usage: "Assign `PRE` to the prerelease version string",
-			},
-			&cli.StringFlag{
-				Name:  "build",
-				Usage: "Assign `BUILD` to the build version string",
-			},
+		Flags: append(bumpFlags(), &cli.BoolFlag{
+			Name:  "no-commit",
+			Usage: "Do not perform a 'git commit' operation, regardless of 'config.noCommit'",
+		}),
+	}
+}
+
+func bumpFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{
+			Name:    "major",
+			Aliases: []string{"maj"},
+			Usage:   "Increase major version number",
+		},
+		&cli.BoolFlag{
+			Name:    "minor",
+			Aliases: []string{"min"},
+			Usage:   "Increase minor version number",
+		},
+		&cli.BoolFlag{
+			Name:    "patch",
+			Aliases: []string{"p"},
+			Usage:   "Increase patch version number",
+		},
+		&cli.BoolFlag{
+			Name:  "auto",
+			Usage: "Increase the version number required by the Conventional Commits since the latest tag",
+		},
+		&cli.StringFlag{
+			Name:  "pre",
+			Usage: "Assign `PRE` to the prerelease version string",
+		},
+		&cli.StringFlag{
+			Name:  "build",
+			Usage: "Assign `BUILD` to the build version string",
 		},
 	}
 }
 
 func bumpAction(ctx context.Context, c *cli.Command) (err error) {
-	cfg, err := config.Load()
-	if err != nil {
-		return
-	}
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
 
-	var v version.Version
-	if err = v.LoadFrom(cfg.VersionPrefix); err != nil {
+	opts, err := bumpOptions(c)
+	if err != nil {
 		return
 	}
+	opts.NoCommit = c.Bool("no-commit")
+	opts.Log = o.msg
 
-	rest := c.Args().Slice()
-
-	if c.Bool("major") {
-		fmt.Printf("\nBumping `major`...\n")
-		v.Major = v.Major + 1
-		v.Minor = 0
-		v.Patch = 0
-		v.Pre = ""
-		v.Build = ""
-	} else if c.Bool("minor") {
-		fmt.Printf("\nBumping `minor`...\n")
-		v.Minor = v.Minor + 1
-		v.Patch = 0
-		v.Pre = ""
-		v.Build = ""
-	} else if c.Bool("patch") {
-		fmt.Printf("\nBumping `patch`...\n")
-		v.Patch = v.Patch + 1
-		v.Pre = ""
-		v.Build = ""
-	} else {
-		if len(rest) > 1 {
-			err = errors.New("Too many options provided")
-			return
-
-		} else if len(rest) == 1 {
-			fmt.Printf("\nBumping to custom version: %s...\n", rest[0])
-			if err = v.Parse(rest[0]); err != nil {
-				return
-			}
-		}
-	}
-
-	if c.String("pre") != "" {
-		fmt.Printf("\nAdding `pre`: %s...\n", c.String("pre"))
-		v.Pre = c.String("pre")
-	}
-	if c.String("build") != "" {
-		fmt.Printf("\nAdding `build`: %s...\n", c.String("build"))
-		v.Build = c.String("build")
+	res, err := release.Bump(ctx, opts)
+	for _, w := range res.Warnings {
+		o.Warnf("%v", w)
 	}
-
-	if err = v.SaveTo(cfg.VersionPrefix); err != nil {
+	if err != nil {
 		return
 	}
 
-	slist := []string{
-		filepath.Join(".", config.Filename),
-		filepath.Join(cfg.VersionPrefix, version.Filename),
-	}
-
-	for _, p := range cfg.NPMPrefixes {
-		var jsonFiles []string
-		if jsonFiles, err = updatePackageJSON(p, v); err != nil {
-			return
-		}
-
-		for _, f := range jsonFiles {
-			slist = append(slist, f)
-		}
-	}
-
-	if !cfg.NoCommit {
-		fmt.Printf("\nCommitting files...\n")
-
-		if err = cfg.Git.CommitFiles(slist, "Bump version"); err != nil {
-			return
-		}
-	}
-
-	fmt.Printf("Done!\n\nNext tag will be: %v\n", v.String())
+	o.res.OldVersion = res.OldVersion.String()
+	o.res.NewVersion = res.NewVersion.String()
+	o.res.Commit = res.Commit
+	o.AddFiles(res.Files...)
 	return
 }
 
-func checkVersionInSync(ctx context.Context, c *cli.Command) (context.Context, error) {
-	var err error
-
-	cfg, err := config.Load()
-	if err != nil {
-		return ctx, err
-	}
-
-	var vFromFile version.Version
-	var tag string
-
-	if err = vFromFile.LoadFrom(cfg.VersionPrefix); err != nil {
-		return ctx, err
-	}
-
-	if tag, err = cfg.Git.LatestTag(cfg.NoFetch); err != nil {
-		fmt.Printf("WARNING, unable to obtain latest tag: %v\n", err)
-		err = nil
-		return ctx, err
-	}
-
-	var ok bool
-	if ok, err = vFromFile.EqualsString(tag); err != nil || !ok {
-		return ctx, err
-	}
-
-	if !ok {
-		err = errors.New("Version in file does not match latest tag. Please sync")
+// bumpOptions maps the bump flags to the release options
+func bumpOptions(c *cli.Command) (opts release.BumpOptions, err error) {
+	switch {
+	case c.Bool("major"):
+		opts.Increment = release.Major
+	case c.Bool("minor"):
+		opts.Increment = release.Minor
+	case c.Bool("patch"):
+		opts.Increment = release.Patch
+	case c.Bool("auto"):
+		opts.Increment = release.Auto
+	default:
+		if c.Args().Len() > 1 {
+			err = release.ErrTooManyOptions
+			return
+		}
+		opts.Version = c.Args().First()
 	}
 
-	return ctx, err
-}
-
-func updatePackageJSON(prefix string, v version.Version) (files []string, err error) {
-	if _, err = exec.Command("npm", "version", "--prefix", prefix, "--no-git-tag-version", v.String()).CombinedOutput(); err != nil {
-		return
-	}
-	files = append(files, filepath.Join(prefix, "package.json"))
-	files = append(files, filepath.Join(prefix, "package-lock.json"))
+	opts.Pre = c.String("pre")
+	opts.Build = c.String("build")
 	return
 }
@@ -0,0 +1,169 @@
+package task
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/release"
+)
+
+func TestBump(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	res := r.mustRun("bump", "--minor")
+	if res.OldVersion != "v0.1.0" || res.NewVersion != "v0.2.0" {
+		t.Errorf("bumped %v -> %v, want v0.1.0 -> v0.2.0", res.OldVersion, res.NewVersion)
+	}
+	if res.Commit == "" || res.Commit != r.head() {
+		t.Errorf("commit = %q, want HEAD %q", res.Commit, r.head())
+	}
+	if got := r.fake.Commits[len(r.fake.Commits)-1].Subject; got != "Bump version" {
+		t.Errorf("last commit = %q, want %q", got, "Bump version")
+	}
+	if !slices.Contains(res.Files, "version.json") {
+		t.Errorf("version.json not in %v", res.Files)
+	}
+	if res.Tag != "" {
+		t.Errorf("bump reported tag %q, without creating it", res.Tag)
+	}
+}
+
+func TestBumpNoCommit(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	res := r.mustRun("bump", "--no-commit", "--pre", "rc.1", "2.0.0")
+	if res.NewVersion != "v2.0.0-rc.1" {
+		t.Errorf("new version = %v, want v2.0.0-rc.1", res.NewVersion)
+	}
+	if res.Commit != "" || len(r.fake.Commits) != 1 {
+		t.Errorf("committed despite --no-commit: %+v", r.fake.Commits)
+	}
+}
+
+func TestBumpAuto(t *testing.T) {
+	tests := []struct {
+		subject string
+		want    string
+	}{
+		{"fix: handle empty input", "v1.0.1"},
+		{"feat: add option", "v1.1.0"},
+		{"feat!: drop option", "v2.0.0"},
+	}
+
+	for _, tt := range tests {
+		r := newFakeRepo(t)
+		r.release("1.0.0")
+		r.commit(tt.subject, map[string]string{"main.go": "package main\n"})
+
+		res := r.mustRun("bump", "--auto")
+		if res.NewVersion != tt.want {
+			t.Errorf("%v: new version = %v, want %v", tt.subject, res.NewVersion, tt.want)
+		}
+	}
+}
+
+func TestBumpErrors(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	if _, err := r.run("bump", "1.0.0", "2.0.0"); !errors.Is(err, release.ErrTooManyOptions) {
+		t.Errorf("got %v, want %v", err, release.ErrTooManyOptions)
+	}
+
+	r.fake.Err = errFake
+	res, err := r.run("bump", "--patch")
+	if !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+	if len(res.Warnings) == 0 {
+		t.Error("expected a warning about the latest tag")
+	}
+}
+
+func TestBumpInvalidTemplate(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+	r.write("version.tmpl", "{{.Version\n")
+
+	cfg := r.read(config.Filename)
+	r.write(config.Filename, strings.Replace(cfg, "{", `{"generate": [{"target": "VERSION", "template": "version.tmpl"}],`, 1))
+
+	if _, err := r.run("bump", "--patch"); err == nil || !strings.Contains(err.Error(), "version.tmpl") {
+		t.Errorf("got %v, want an invalid template error", err)
+	}
+	if len(r.fake.Commits) != 1 {
+		t.Errorf("committed despite the invalid template: %+v", r.fake.Commits)
+	}
+}
+
+func TestBumpProfiles(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+	t.Setenv("GITHUB_HEAD_REF", "")
+
+	cfg := r.read(config.Filename)
+	r.write(config.Filename, strings.Replace(cfg, "{", `{"profiles": [
+		{"branch": "maint/*", "increments": ["patch"]},
+		{"branch": "feature/*", "pre": "{branch}", "noCommit": true}
+	],`, 1))
+	r.mustRun("config", "--persist")
+	commits := len(r.fake.Commits)
+
+	r.fake.CurrentBranch = "maint/1.x"
+	if _, err := r.run("bump", "--minor"); !errors.Is(err, release.ErrNotAllowed) {
+		t.Errorf("minor on maint/1.x: got %v, want %v", err, release.ErrNotAllowed)
+	}
+	if res := r.mustRun("bump", "--patch"); res.NewVersion != "v0.1.1" {
+		t.Errorf("patch on maint/1.x = %v, want v0.1.1", res.NewVersion)
+	}
+	commits++
+
+	tests := []struct {
+		branch string
+		env    string
+		args   []string
+		want   string
+	}{
+		{"feature/login", "", []string{"--minor"}, "v0.2.0-feature-login"},
+		{"feature/login", "", []string{"--minor", "--pre", "rc.1"}, "v0.2.0-rc.1"},
+		{"", "feature/from-ci", []string{"--major"}, "v1.0.0-feature-from-ci"},
+	}
+
+	for _, tt := range tests {
+		r.fake.CurrentBranch = tt.branch
+		t.Setenv("GITHUB_HEAD_REF", tt.env)
+
+		res := r.mustRun(append([]string{"bump"}, tt.args...)...)
+		if res.NewVersion != tt.want {
+			t.Errorf("%v%v %v: new version = %v, want %v", tt.branch, tt.env, tt.args, res.NewVersion, tt.want)
+		}
+		if len(r.fake.Commits) != commits {
+			t.Errorf("%v%v: committed despite the profile's noCommit", tt.branch, tt.env)
+		}
+		r.write("version.json", `{"major": 0, "minor": 1, "patch": 1, "pre": "", "build": ""}`)
+	}
+}
+
+func TestNext(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.2.3")
+
+	res := r.mustRun("next", "--major")
+	if res.OldVersion != "v1.2.3" || res.NewVersion != "v2.0.0" {
+		t.Errorf("next %v -> %v, want v1.2.3 -> v2.0.0", res.OldVersion, res.NewVersion)
+	}
+
+	res = r.mustRun("next", "--patch", "--format", "{{.Major}}.{{.Minor}}.{{.Patch}}")
+	if res.Formatted != "1.2.4" {
+		t.Errorf("formatted = %q, want 1.2.4", res.Formatted)
+	}
+
+	if res := r.mustRun("version"); res.Version != "v1.2.3" {
+		t.Errorf("next changed the version to %v", res.Version)
+	}
+}
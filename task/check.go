@@ -0,0 +1,212 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/conventional"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+// Exit codes of the check command
+const (
+	checkExitFailed = 1
+	checkExitError  = 2
+)
+
+// Check verifies that the version was bumped relative to a base revision.
+func Check() *cli.Command {
+	return &cli.Command{
+		Name:            "check",
+		Category:        "Informational",
+		Usage:           "Check version bump against a base revision",
+		UsageText:       "check [--base REV] [--ignore GLOB]...",
+		Description:     "Verifies that, if files changed since the base revision, the version file was bumped, the new version is greater than the one of the base revision, and the bump level is not smaller than the one required by the Conventional Commits in the range. Exits with status 1 if a check fails, and 2 if the checks could not be performed",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          checkAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "base",
+				Value: "origin/main",
+				Usage: "Base `REV` to compare against, e.g., the target branch of a pull request",
+			},
+			&cli.StringSliceFlag{
+				Name:  "ignore",
+				Value: []string{"*.md"},
+				Usage: "Ignore changes in files matching `GLOB`, either by path or base name",
+			},
+		},
+	}
+}
+
+func checkAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	defer func() {
+		for _, chk := range o.res.Checks {
+			o.Println(fmt.Sprintf("[%-4s] %-8s %v", chk.Status, chk.Name, chk.Message))
+			if chk.Status == checkFail && os.Getenv("GITHUB_ACTIONS") == "true" {
+				o.Println(fmt.Sprintf("::error title=bumpy check (%v)::%v", chk.Name, chk.Message))
+			}
+		}
+
+		if err != nil {
+			err = cli.Exit(err.Error(), checkExitError)
+			return
+		}
+
+		for _, chk := range o.res.Checks {
+			if chk.Status == checkFail {
+				err = cli.Exit("Version check failed", checkExitFailed)
+				break
+			}
+		}
+	}()
+
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+	if !cfg.HasVCS() {
+		err = vcs.ErrNoRepository
+		return
+	}
+
+	base := c.String("base")
+
+	mergeBase, err := cfg.VCS.MergeBase(base, "HEAD")
+	if err != nil {
+		err = fmt.Errorf("Unable to find a common ancestor with %v (is it fetched?): %w", base, err)
+		return
+	}
+	o.res.Commit = mergeBase
+
+	versionFile := cfg.RepoPath(filepath.Join(cfg.VersionPrefix, version.Filename))
+
+	// the version is read from HEAD, as the base version and the diff are,
+	// so that uncommitted changes are not taken into account
+	var v version.Version
+	b, err := cfg.VCS.Show("HEAD", versionFile)
+	if err != nil {
+		err = fmt.Errorf("Unable to read %v at HEAD: %w", versionFile, err)
+		return
+	}
+	if err = v.Read(b); err != nil {
+		err = fmt.Errorf("Unable to read %v at HEAD: %w", versionFile, err)
+		return
+	}
+	o.SetVersion(v)
+
+	var bv version.Version
+	hasBase := false
+	if b, sErr := cfg.VCS.Show(mergeBase, versionFile); sErr == nil {
+		if err = bv.Read(b); err != nil {
+			err = fmt.Errorf("Unable to read %v at %v: %w", versionFile, base, err)
+			return
+		}
+		hasBase = true
+		o.res.OldVersion = bv.String()
+	}
+	o.res.NewVersion = v.String()
+
+	files, err := cfg.VCS.Diff(mergeBase, "")
+	if err != nil {
+		return
+	}
+
+	var changed []string
+	bumped := false
+	for _, f := range files {
+		if f == versionFile {
+			bumped = true
+			continue
+		}
+		if !ignoredFile(f, c.StringSlice("ignore")) {
+			changed = append(changed, f)
+		}
+	}
+	o.AddFiles(changed...)
+
+	if len(changed) == 0 {
+		o.check("changes", checkOK, "no relevant files changed since %v", base)
+		return
+	}
+	o.check("changes", checkOK, "%v file(s) changed since %v", len(changed), base)
+
+	if !hasBase {
+		o.check("bumped", checkOK, "%v is new, nothing to compare against", versionFile)
+		return
+	}
+
+	if !bumped || v.Equals(bv) {
+		o.check("bumped", checkFail, "%v was not bumped, still %v", versionFile, bv.String())
+		return
+	}
+	o.check("bumped", checkOK, "%v was bumped", versionFile)
+
+	if v.Compare(bv) <= 0 {
+		o.check("greater", checkFail, "%v is not greater than %v", v.String(), bv.String())
+		return
+	}
+	o.check("greater", checkOK, "%v is greater than %v", v.String(), bv.String())
+
+	commits, err := cfg.VCS.Log(mergeBase, "")
+	if err != nil {
+		return
+	}
+
+	required := conventional.None
+	for _, cm := range commits {
+		required = conventional.Max(required, conventional.LevelOf(cm.Subject, cm.Body))
+	}
+
+	actual := bumpLevel(bv, v)
+	switch {
+	case bv.Pre != "" && sameCore(bv, v):
+		o.check("level", checkOK, "%v continues the prerelease line of %v", v.String(), bv.String())
+	case actual < required:
+		o.check("level", checkFail, "%v bump, but commits since %v require %v", actual, base, required)
+	default:
+		o.check("level", checkOK, "%v bump, commits since %v require %v", actual, base, required)
+	}
+
+	return
+}
+
+// bumpLevel returns the level of the increment from one version to another
+func bumpLevel(from, to version.Version) conventional.Level {
+	switch {
+	case to.Major != from.Major:
+		return conventional.Major
+	case to.Minor != from.Minor:
+		return conventional.Minor
+	case to.Patch != from.Patch:
+		return conventional.Patch
+	}
+	return conventional.None
+}
+
+func sameCore(a, b version.Version) bool {
+	return a.Major == b.Major && a.Minor == b.Minor && a.Patch == b.Patch
+}
+
+func ignoredFile(file string, patterns []string) bool {
+	for _, p := range patterns {
+		if ok, _ := path.Match(p, file); ok {
+			return true
+		}
+		if ok, _ := path.Match(p, path.Base(file)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package task
+
+import (
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		commit string
+		files  map[string]string
+		bump   []string
+		code   int
+		failed string
+	}{
+		{"docs only", "docs: update README", map[string]string{"README.md": "# App\n"}, nil, 0, ""},
+		{"not bumped", "fix: handle empty input", map[string]string{"main.go": "package main\n"}, nil, checkExitFailed, "bumped"},
+		{"bumped", "fix: handle empty input", map[string]string{"main.go": "package main\n"}, []string{"--patch"}, 0, ""},
+		{"level too small", "feat: add option", map[string]string{"main.go": "package main\n"}, []string{"--patch"}, checkExitFailed, "level"},
+		{"level", "feat: add option", map[string]string{"main.go": "package main\n"}, []string{"--minor"}, 0, ""},
+	}
+
+	for _, tt := range tests {
+		r := newFakeRepo(t)
+		r.release("1.0.0")
+		base := r.head()
+
+		r.commit(tt.commit, tt.files)
+		if tt.bump != nil {
+			r.mustRun(append([]string{"bump"}, tt.bump...)...)
+		}
+
+		res, err := r.run("check", "--base", base)
+		if tt.code == 0 && err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.name, err)
+		} else if tt.code != 0 && exitCode(err) != tt.code {
+			t.Errorf("%v: got %v, want exit code %v", tt.name, err, tt.code)
+		}
+		if tt.failed != "" && checkOf(res, tt.failed) != checkFail {
+			t.Errorf("%v: %v check did not fail: %+v", tt.name, tt.failed, res.Checks)
+		}
+		if res.Commit != base {
+			t.Errorf("%v: merge base = %v, want %v", tt.name, res.Commit, base)
+		}
+	}
+}
+
+func TestCheckUncommittedBump(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+	base := r.head()
+	r.commit("fix: handle empty input", map[string]string{"main.go": "package main\n"})
+	r.mustRun("bump", "--patch", "--no-commit")
+
+	res, err := r.run("check", "--base", base)
+	if exitCode(err) != checkExitFailed || checkOf(res, "bumped") != checkFail {
+		t.Errorf("uncommitted bump accepted: %v, %+v", err, res.Checks)
+	}
+}
+
+func TestCheckErrors(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+
+	if _, err := r.run("check", "--base", "origin/main"); exitCode(err) != checkExitError {
+		t.Errorf("missing base: got %v, want exit code %v", err, checkExitError)
+	}
+
+	r.fake.Err = errFake
+	if _, err := r.run("check", "--base", "HEAD"); exitCode(err) != checkExitError {
+		t.Errorf("VCS error: got %v, want exit code %v", err, checkExitError)
+	}
+}
+
+func TestCheckNoRepository(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init")
+	config.OpenVCS = func(dir, _ string) (vcs.VCS, error) { return vcs.NewNone(dir), nil }
+
+	res, err := r.run("check")
+	if exitCode(err) != checkExitError || err.Error() != vcs.ErrNoRepository.Error() {
+		t.Errorf("got %v, want %v with exit code %v", err, vcs.ErrNoRepository, checkExitError)
+	}
+	if res.Error != vcs.ErrNoRepository.Error() {
+		t.Errorf("unexpected JSON error: %q", res.Error)
+	}
+}
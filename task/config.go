@@ -2,10 +2,14 @@ package task
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"path/filepath"
+	"strings"
+	"text/tabwriter"
 
 	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
 	"github.com/urfave/cli/v3"
 )
 
@@ -17,12 +21,19 @@ func Config() *cli.Command {
 		Category:        "Control",
 		Usage:           "Modify the version config file",
 		UsageText:       "config [<flags>...] ...",
-		Description:     "Modify the version configuration file and display its contents",
+		Description:     "Modify the version configuration file and display its contents. With --show-origin, display instead the effective configuration, layered from the built-in defaults, the user-level config file, the version configuration file, the BUMPY_* environment variables and the setting flags given, which are not saved, along with the origin of each value. The 'migrate' subcommand upgrades the file to the current schema version",
 		SkipFlagParsing: false,
 		HideHelp:        false,
 		Hidden:          false,
 		Action:          configAction,
+		Commands: []*cli.Command{
+			configMigrate(),
+		},
 		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "show-origin",
+				Usage: "Display the effective config and where each value comes from, without saving the other flags",
+			},
 			&cli.BoolFlag{
 				Name:  "persist",
 				Usage: "Perform a 'git commit' for the config udate",
@@ -59,16 +70,81 @@ func Config() *cli.Command {
 				Name:  "clear-npm-prefixes",
 				Usage: "Clears the list of npm prefixes in the config",
 			},
+			&cli.StringSliceFlag{
+				Name:  "add-cargo-prefix",
+				Usage: "Add subdirectory to Cargo prefixes",
+			},
+			&cli.StringSliceFlag{
+				Name:  "remove-cargo-prefix",
+				Usage: "Remove subdirectory from Cargo prefixes",
+			},
+			&cli.BoolFlag{
+				Name:  "clear-cargo-prefixes",
+				Usage: "Clears the list of Cargo prefixes in the config",
+			},
+			&cli.StringSliceFlag{
+				Name:  "add-maven-prefix",
+				Usage: "Add subdirectory to Maven prefixes",
+			},
+			&cli.StringSliceFlag{
+				Name:  "remove-maven-prefix",
+				Usage: "Remove subdirectory from Maven prefixes",
+			},
+			&cli.BoolFlag{
+				Name:  "clear-maven-prefixes",
+				Usage: "Clears the list of Maven prefixes in the config",
+			},
+			&cli.StringSliceFlag{
+				Name:  "add-gradle-prefix",
+				Usage: "Add subdirectory to Gradle prefixes",
+			},
+			&cli.StringSliceFlag{
+				Name:  "remove-gradle-prefix",
+				Usage: "Remove subdirectory from Gradle prefixes",
+			},
+			&cli.BoolFlag{
+				Name:  "clear-gradle-prefixes",
+				Usage: "Clears the list of Gradle prefixes in the config",
+			},
+			&cli.StringFlag{
+				Name:  "ldflags-version-var",
+				Usage: "Variable set to the version by the ldflags command, persistent as 'config.ldflags.version'. Use '-' to omit it",
+			},
+			&cli.StringFlag{
+				Name:  "ldflags-commit-var",
+				Usage: "Variable set to the commit by the ldflags command, persistent as 'config.ldflags.commit'. Use '-' to omit it",
+			},
+			&cli.StringFlag{
+				Name:  "ldflags-date-var",
+				Usage: "Variable set to the date by the ldflags command, persistent as 'config.ldflags.date'. Use '-' to omit it",
+			},
+			&cli.StringFlag{
+				Name:  "vcs",
+				Usage: "VCS `BACKEND`, either 'git', 'go-git' or 'hg', persistent as 'config.vcs'. Use 'auto' to detect it",
+			},
 		},
 	}
 }
 
 func configAction(ctx context.Context, c *cli.Command) (err error) {
-	cfg, err := config.Load()
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	if c.Bool("show-origin") {
+		err = configShowOrigin(o, configFlags(c))
+		return
+	}
+
+	cfg, err := config.LoadUnchecked()
 	if err != nil {
 		return
 	}
 
+	if c.Bool("persist") && !cfg.HasVCS() {
+		err = fmt.Errorf("%w, unable to persist", vcs.ErrNoRepository)
+		return
+	}
+
 	if c.Bool("no-fetch") {
 		cfg.NoFetch = true
 	} else if c.Bool("fetch") {
@@ -92,42 +168,239 @@ func configAction(ctx context.Context, c *cli.Command) (err error) {
 	}
 
 	if len(c.StringSlice("remove-npm-prefix")) > 0 {
-		newSlice := []string{}
-		// TODO: optimize loop
-	outerLoop:
-		for _, v := range cfg.NPMPrefixes {
-			for _, p := range c.StringSlice("remove-npm-prefix") {
-				if v == p {
-					continue outerLoop
-				}
-			}
-			newSlice = append(newSlice, v)
-		}
-		cfg.NPMPrefixes = newSlice
+		cfg.NPMPrefixes = removePrefixes(cfg.NPMPrefixes, c.StringSlice("remove-npm-prefix"))
 	}
 
 	if c.Bool("clear-npm-prefixes") {
 		cfg.NPMPrefixes = []string{}
 	}
 
+	if len(c.StringSlice("add-cargo-prefix")) > 0 {
+		cfg.CargoPrefixes = append(cfg.CargoPrefixes, c.StringSlice("add-cargo-prefix")...)
+	}
+
+	if len(c.StringSlice("remove-cargo-prefix")) > 0 {
+		cfg.CargoPrefixes = removePrefixes(cfg.CargoPrefixes, c.StringSlice("remove-cargo-prefix"))
+	}
+
+	if c.Bool("clear-cargo-prefixes") {
+		cfg.CargoPrefixes = []string{}
+	}
+
+	if len(c.StringSlice("add-maven-prefix")) > 0 {
+		cfg.MavenPrefixes = append(cfg.MavenPrefixes, c.StringSlice("add-maven-prefix")...)
+	}
+
+	if len(c.StringSlice("remove-maven-prefix")) > 0 {
+		cfg.MavenPrefixes = removePrefixes(cfg.MavenPrefixes, c.StringSlice("remove-maven-prefix"))
+	}
+
+	if c.Bool("clear-maven-prefixes") {
+		cfg.MavenPrefixes = []string{}
+	}
+
+	if len(c.StringSlice("add-gradle-prefix")) > 0 {
+		cfg.GradlePrefixes = append(cfg.GradlePrefixes, c.StringSlice("add-gradle-prefix")...)
+	}
+
+	if len(c.StringSlice("remove-gradle-prefix")) > 0 {
+		cfg.GradlePrefixes = removePrefixes(cfg.GradlePrefixes, c.StringSlice("remove-gradle-prefix"))
+	}
+
+	if c.Bool("clear-gradle-prefixes") {
+		cfg.GradlePrefixes = []string{}
+	}
+
+	if c.IsSet("ldflags-version-var") || c.IsSet("ldflags-commit-var") || c.IsSet("ldflags-date-var") {
+		if cfg.LDFlags == nil {
+			cfg.LDFlags = &config.LDFlags{
+				Version: defaultVersionVar,
+				Commit:  defaultCommitVar,
+				Date:    defaultDateVar,
+			}
+		}
+		if c.IsSet("ldflags-version-var") {
+			cfg.LDFlags.Version = c.String("ldflags-version-var")
+		}
+		if c.IsSet("ldflags-commit-var") {
+			cfg.LDFlags.Commit = c.String("ldflags-commit-var")
+		}
+		if c.IsSet("ldflags-date-var") {
+			cfg.LDFlags.Date = c.String("ldflags-date-var")
+		}
+	}
+
+	if c.IsSet("vcs") {
+		cfg.Backend = c.String("vcs")
+		if cfg.Backend == "auto" {
+			cfg.Backend = vcs.BackendAuto
+		}
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return
+	}
+
+	err = saveConfig(o, cfg, c.Bool("persist"), "Update version config")
+	return
+}
+
+// configFlags returns the setting flags given to the config command, as the
+// flag layer of the configuration
+func configFlags(c *cli.Command) (flags []config.Flag) {
+	for _, f := range []struct {
+		name, key string
+		value     any
+	}{
+		{"fetch", "noFetch", false},
+		{"no-fetch", "noFetch", true},
+		{"commit", "noCommit", false},
+		{"no-commit", "noCommit", true},
+	} {
+		if c.Bool(f.name) {
+			flags = append(flags, config.Flag{Name: "--" + f.name, Key: f.key, Value: f.value})
+		}
+	}
+
+	for _, f := range []struct{ name, key string }{
+		{"version-prefix", "versionPrefix"},
+		{"ldflags-version-var", "ldflags.version"},
+		{"ldflags-commit-var", "ldflags.commit"},
+		{"ldflags-date-var", "ldflags.date"},
+		{"vcs", "vcs"},
+	} {
+		if !c.IsSet(f.name) {
+			continue
+		}
+
+		value := c.String(f.name)
+		if f.name == "vcs" && value == "auto" {
+			value = vcs.BackendAuto
+		}
+		flags = append(flags, config.Flag{Name: "--" + f.name, Key: f.key, Value: value})
+	}
+	return
+}
+
+// configShowOrigin displays the effective config values and their origin,
+// with the given flags as the last layer
+func configShowOrigin(o *output, flags []config.Flag) (err error) {
+	cfg, err := config.Load(flags...)
+	if err != nil {
+		return
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	w.Write([]byte("ORIGIN\tKEY\tVALUE\n"))
+
+	for _, s := range cfg.Settings() {
+		value, ok := s.Value.(string)
+		if !ok {
+			bv, _ := json.Marshal(s.Value)
+			value = string(bv)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\n", s.Origin, s.Key, value)
+	}
+	w.Flush()
+
+	o.res.Settings = cfg.Settings()
+	o.Printf("%v", sb.String())
+	return
+}
+
+// configMigrate upgrades the config file to the current schema version
+func configMigrate() *cli.Command {
+	return &cli.Command{
+		Name:            "migrate",
+		Usage:           "Upgrade the version config file",
+		UsageText:       "config migrate [--persist]",
+		Description:     "Upgrades the version configuration file to the current schema version, and displays its contents",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          configMigrateAction,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:  "persist",
+				Usage: "Perform a 'git commit' for the config upgrade",
+			},
+		},
+	}
+}
+
+func configMigrateAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	cfg, err := config.LoadUnchecked()
+	if err != nil {
+		return
+	}
+
+	from := cfg.FileSchemaVersion()
+	if from == config.SchemaVersion {
+		o.Printf("Config file already at schema version %v\n", from)
+		o.res.Config = cfg
+		return
+	}
+
+	if c.Bool("persist") && !cfg.HasVCS() {
+		err = fmt.Errorf("%w, unable to persist", vcs.ErrNoRepository)
+		return
+	}
+
+	if err = cfg.Validate(); err != nil {
+		return
+	}
+
+	o.Printf("Migrating config file from schema version %v to %v...\n", from, config.SchemaVersion)
+	err = saveConfig(o, cfg, c.Bool("persist"), "Migrate version config")
+	return
+}
+
+// saveConfig writes the config file and displays its contents, committing it
+// if requested
+func saveConfig(o *output, cfg *config.Config, persist bool, msg string) (err error) {
 	if err = cfg.Save(); err != nil {
 		return
 	}
 
-	bv, err := config.GetBytes()
+	bv, err := cfg.Marshal()
 	if err != nil {
 		return
 	}
 
-	if c.Bool("persist") {
-		if err = cfg.Git.CommitFiles(
-			[]string{filepath.Join(".", config.Filename)},
-			"Update version config",
+	if persist {
+		if err = cfg.VCS.CommitFiles(
+			[]string{cfg.Path(cfg.File)},
+			msg,
 		); err != nil {
 			return
 		}
+
+		if o.res.Commit, _, err = headCommit(cfg); err != nil {
+			return
+		}
+		o.AddFiles(filepath.Join(".", cfg.File))
 	}
 
-	fmt.Printf("%v\n", string(bv))
+	o.res.Config = cfg
+	o.Println(strings.TrimSuffix(string(bv), "\n"))
 	return
 }
+
+func removePrefixes(list, remove []string) []string {
+	newSlice := []string{}
+	// TODO: optimize loop
+outerLoop:
+	for _, v := range list {
+		for _, p := range remove {
+			if v == p {
+				continue outerLoop
+			}
+		}
+		newSlice = append(newSlice, v)
+	}
+	return newSlice
+}
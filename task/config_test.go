@@ -0,0 +1,105 @@
+package task
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+)
+
+func TestConfig(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+	r.write("crates/Cargo.toml", "[workspace]\n")
+
+	res := r.mustRun("config", "--no-fetch", "--add-cargo-prefix", "crates", "--persist")
+	if res.Config == nil || !res.Config.NoFetch || len(res.Config.CargoPrefixes) != 1 {
+		t.Errorf("unexpected config: %+v", res.Config)
+	}
+	if got := r.fake.Commits[len(r.fake.Commits)-1].Subject; got != "Update version config" {
+		t.Errorf("last commit = %q, want %q", got, "Update version config")
+	}
+	if res.Commit != r.head() {
+		t.Errorf("commit = %q, want HEAD %q", res.Commit, r.head())
+	}
+
+	res = r.mustRun("config", "--fetch", "--remove-cargo-prefix", "crates")
+	if res.Config.NoFetch || len(res.Config.CargoPrefixes) != 0 {
+		t.Errorf("unexpected config: %+v", res.Config)
+	}
+}
+
+func TestConfigShowOrigin(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+	r.mustRun("config", "--no-fetch")
+	t.Setenv("BUMPY_NO_COMMIT", "true")
+
+	for _, tc := range []struct {
+		args []string
+		want map[string]string
+	}{
+		{
+			[]string{"config", "--show-origin"},
+			map[string]string{
+				"noFetch":  "file:" + config.Filename,
+				"noCommit": "env:BUMPY_NO_COMMIT",
+			},
+		},
+		{
+			[]string{"config", "--show-origin", "--fetch", "--vcs", "go-git"},
+			map[string]string{
+				"noFetch":  "flag:--fetch",
+				"noCommit": "env:BUMPY_NO_COMMIT",
+				"vcs":      "flag:--vcs",
+			},
+		},
+	} {
+		res := r.mustRun(tc.args...)
+		origins := map[string]string{}
+		for _, s := range res.Settings {
+			origins[s.Key] = s.Origin
+		}
+
+		for k, origin := range tc.want {
+			if origins[k] != origin {
+				t.Errorf("%v: %v: origin = %q, want %q", tc.args, k, origins[k], origin)
+			}
+		}
+	}
+
+	// the flags shown are not saved
+	if res := r.mustRun("config"); !res.Config.NoFetch || res.Config.Backend != "" {
+		t.Errorf("flags saved by --show-origin: %+v", res.Config)
+	}
+}
+
+func TestConfigMigrate(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	res := r.mustRun("config", "migrate", "--persist")
+	if res.Config == nil || res.Config.SchemaVersion != config.SchemaVersion {
+		t.Errorf("unexpected config: %+v", res.Config)
+	}
+	if len(r.fake.Commits) != 1 {
+		t.Errorf("committed an up-to-date config: %+v", r.fake.Commits)
+	}
+}
+
+func TestConfigErrors(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	if _, err := r.run("config", "--vcs", "svn"); err == nil {
+		t.Error("expected error for an unsupported backend")
+	}
+	if _, err := r.run("config", "--ldflags-version-var", "Version"); err == nil {
+		t.Error("expected error for an invalid ldflags variable")
+	}
+
+	r.fake.Err = errFake
+	if _, err := r.run("config", "--no-fetch", "--persist"); !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+}
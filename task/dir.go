@@ -0,0 +1,48 @@
+package task
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/urfave/cli/v3"
+)
+
+// DirFlag returns the global flag changing the working directory
+func DirFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:  "C",
+		Usage: "Run as if bumpy was started in `DIR`",
+	}
+}
+
+// Before changes to the directory given through DirFlag, if any, as `git -C`
+// does, so that the paths given on the command line are relative to it. The
+// closest configuration file is looked up from there by every command, and
+// the paths it configures are resolved relative to its own directory. Errors
+// are reported in the JSON output, if requested, as those of commands are
+func Before(ctx context.Context, c *cli.Command) (_ context.Context, err error) {
+	if dir := c.String("C"); dir != "" {
+		if err = os.Chdir(dir); err != nil {
+			o := newOutput(ctx, c)
+			if name := c.Args().First(); name != "" {
+				o.res.Command = name
+			}
+			o.Flush(&err)
+		}
+	}
+	return ctx, err
+}
+
+// argPath returns the given path, as typed on the command line, as an
+// absolute one, so that it does not depend on the configuration directory.
+// An empty path is returned as is
+func argPath(p string) string {
+	if p == "" {
+		return p
+	}
+	if abs, err := filepath.Abs(p); err == nil {
+		return abs
+	}
+	return p
+}
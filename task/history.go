@@ -0,0 +1,209 @@
+package task
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+// versionTag is a tag whose name parses as a version
+type versionTag struct {
+	vcs.Tag
+	Version   version.Version
+	Component string
+}
+
+// historyEntry is a single release of the history
+type historyEntry struct {
+	Tag        string    `json:"tag"`
+	Version    string    `json:"version"`
+	Component  string    `json:"component,omitempty"`
+	Date       time.Time `json:"date"`
+	Commit     string    `json:"commit"`
+	Annotated  bool      `json:"annotated"`
+	Prerelease bool      `json:"prerelease"`
+	Summary    string    `json:"summary,omitempty"`
+}
+
+// History lists the releases of the repository.
+func History() *cli.Command {
+	return &cli.Command{
+		Name:            "history",
+		Aliases:         []string{"hist"},
+		Category:        "Informational",
+		Usage:           "List releases",
+		UsageText:       "history [--range CONSTRAINT] [--major N] [--component NAME] [--no-pre] ...",
+		Description:     "Lists all version tags, sorted by SemVer precedence, with their date, commit, annotation summary and prerelease flag",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          historyAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "range",
+				Aliases: []string{"r"},
+				Usage:   "List only versions satisfying `CONSTRAINT`, e.g.: '>=1.2.0 <2.0.0', '^1.2', '~1.2.3' or '1.x'",
+			},
+			&cli.IntFlag{
+				Name:  "major",
+				Usage: "List only versions of the given `MAJOR` line",
+			},
+			&cli.StringFlag{
+				Name:  "component",
+				Usage: "List only tags of the given `COMPONENT`, named like 'COMPONENT/v1.2.3'",
+			},
+			&cli.BoolFlag{
+				Name:  "no-pre",
+				Usage: "Omit prereleases",
+			},
+			&cli.BoolFlag{
+				Name:  "reverse",
+				Usage: "List oldest versions first",
+			},
+			&cli.IntFlag{
+				Name:  "limit",
+				Usage: "List at most `N` versions",
+			},
+		},
+	}
+}
+
+func historyAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+
+	var constraint *version.Constraint
+	if c.String("range") != "" {
+		var cons version.Constraint
+		if cons, err = version.ParseConstraint(c.String("range")); err != nil {
+			return
+		}
+		constraint = &cons
+	}
+
+	tags, err := versionTags(cfg, c.String("component"))
+	if err != nil {
+		return
+	}
+
+	if c.Bool("reverse") {
+		for i, j := 0, len(tags)-1; i < j; i, j = i+1, j-1 {
+			tags[i], tags[j] = tags[j], tags[i]
+		}
+	}
+
+	var sb strings.Builder
+	w := tabwriter.NewWriter(&sb, 0, 4, 2, ' ', 0)
+	w.Write([]byte("VERSION\tDATE\tCOMMIT\tPRE\tSUMMARY\n"))
+
+	for _, t := range tags {
+		if constraint != nil && !constraint.Check(t.Version) {
+			continue
+		}
+		if c.IsSet("major") && t.Version.Major != int(c.Int("major")) {
+			continue
+		}
+		if c.Bool("no-pre") && t.Version.Pre != "" {
+			continue
+		}
+		if c.Int("limit") > 0 && len(o.res.History) >= int(c.Int("limit")) {
+			break
+		}
+
+		e := historyEntry{
+			Tag:        t.Name,
+			Version:    t.Version.String(),
+			Component:  t.Component,
+			Date:       t.Date,
+			Commit:     t.Commit,
+			Annotated:  t.Annotated,
+			Prerelease: t.Version.Pre != "",
+			Summary:    t.Subject,
+		}
+		o.res.History = append(o.res.History, e)
+
+		summary := e.Summary
+		if !e.Annotated {
+			summary = "(lightweight)"
+		}
+
+		pre := ""
+		if e.Prerelease {
+			pre = "yes"
+		}
+
+		w.Write([]byte(strings.Join([]string{
+			e.Tag,
+			e.Date.Format("2006-01-02"),
+			shortHash(e.Commit),
+			pre,
+			summary,
+		}, "\t") + "\n"))
+	}
+
+	w.Flush()
+	o.Println(strings.TrimSuffix(sb.String(), "\n"))
+	o.Printf("\n%v release(s)\n", len(o.res.History))
+	return
+}
+
+// versionTags returns the tags of the repository whose names parse as
+// versions, for the given component (if any), sorted by descending SemVer
+// precedence
+func versionTags(cfg *config.Config, component string) (list []versionTag, err error) {
+	tags, err := cfg.VCS.Tags()
+	if err != nil {
+		return
+	}
+
+	for _, t := range tags {
+		comp, name := splitComponent(t.Name)
+		if comp != component {
+			continue
+		}
+
+		vt := versionTag{Tag: t, Component: comp}
+		if err := vt.Version.Parse(name); err != nil {
+			continue
+		}
+		list = append(list, vt)
+	}
+
+	sort.SliceStable(list, func(i, j int) bool {
+		if d := list[i].Version.Compare(list[j].Version); d != 0 {
+			return d > 0
+		}
+		return list[i].Date.After(list[j].Date)
+	})
+
+	return
+}
+
+// splitComponent splits a tag name like `COMPONENT/v1.2.3` into its
+// component and version parts
+func splitComponent(tag string) (component, name string) {
+	i := strings.LastIndex(tag, "/")
+	if i < 0 {
+		return "", tag
+	}
+	return tag[:i], tag[i+1:]
+}
+
+func shortHash(hash string) string {
+	if len(hash) > 7 {
+		return hash[:7]
+	}
+	return hash
+}
@@ -0,0 +1,142 @@
+package task
+
+import (
+	"errors"
+	"testing"
+)
+
+// releases returns a repository with a few releases
+func releases(t *testing.T) *fakeRepo {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+	r.commit("feat: add option", map[string]string{"main.go": "package main\n"})
+	r.release("1.1.0")
+	r.release("2.0.0-rc.1")
+	return r
+}
+
+func TestHistory(t *testing.T) {
+	r := releases(t)
+
+	tests := []struct {
+		args []string
+		want []string
+	}{
+		{nil, []string{"v2.0.0-rc.1", "v1.1.0", "v1.0.0"}},
+		{[]string{"--reverse", "--limit", "1"}, []string{"v1.0.0"}},
+		{[]string{"--no-pre"}, []string{"v1.1.0", "v1.0.0"}},
+		{[]string{"--major", "2"}, []string{"v2.0.0-rc.1"}},
+		{[]string{"--range", "^1.0.1"}, []string{"v1.1.0"}},
+	}
+
+	for _, tt := range tests {
+		res := r.mustRun(append([]string{"history"}, tt.args...)...)
+
+		var got []string
+		for _, e := range res.History {
+			got = append(got, e.Tag)
+		}
+		if len(got) != len(tt.want) {
+			t.Errorf("%v: got %v, want %v", tt.args, got, tt.want)
+			continue
+		}
+		for i := range got {
+			if got[i] != tt.want[i] {
+				t.Errorf("%v: got %v, want %v", tt.args, got, tt.want)
+				break
+			}
+		}
+	}
+}
+
+func TestHistoryErrors(t *testing.T) {
+	r := releases(t)
+
+	if _, err := r.run("history", "--range", "a.b"); err == nil {
+		t.Error("expected error for an invalid range")
+	}
+
+	r.fake.Err = errFake
+	if _, err := r.run("history"); !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+}
+
+func TestShow(t *testing.T) {
+	r := releases(t)
+
+	res := r.mustRun("show", "1.1.0")
+	rel := res.Release
+	if rel == nil {
+		t.Fatal("no release details")
+	}
+	if rel.Tag != "v1.1.0" || rel.Previous != "v1.0.0" || rel.Message != "Release 1.1.0" {
+		t.Errorf("unexpected release: %+v", rel)
+	}
+	if len(rel.Commits) != 2 || rel.Commits[1].Subject != "feat: add option" {
+		t.Errorf("unexpected commits: %+v", rel.Commits)
+	}
+	if rel.VersionFile == "" {
+		t.Error("version file not read at the tag")
+	}
+}
+
+func TestShowErrors(t *testing.T) {
+	r := releases(t)
+
+	for _, args := range [][]string{{"show"}, {"show", "1.0.0", "1.1.0"}, {"show", "9.9.9"}} {
+		if _, err := r.run(args...); err == nil {
+			t.Errorf("%v: expected error", args)
+		}
+	}
+
+	r.fake.Err = errFake
+	if _, err := r.run("show", "1.0.0"); !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+}
+
+func TestAudit(t *testing.T) {
+	r := releases(t)
+
+	res := r.mustRun("audit")
+	if len(res.Audit) != 0 {
+		t.Errorf("unexpected findings: %+v", res.Audit)
+	}
+
+	res = r.mustRun("audit", "--all")
+	if len(res.Audit) != 3 {
+		t.Errorf("got %v finding(s), want one per tag: %+v", len(res.Audit), res.Audit)
+	}
+}
+
+func TestAuditInconsistent(t *testing.T) {
+	r := releases(t)
+	r.tag("v3.0.0")
+	r.tag("latest")
+
+	res, err := r.run("audit")
+	if exitCode(err) != 1 {
+		t.Errorf("got %v, want exit code 1", err)
+	}
+
+	found := map[string]string{}
+	for _, f := range res.Audit {
+		found[f.Tag+" "+f.Check] = f.Status
+	}
+	if found["v3.0.0 version"] != checkFail {
+		t.Errorf("version mismatch not reported: %+v", res.Audit)
+	}
+	if found["latest semver"] != checkWarn {
+		t.Errorf("invalid tag not reported: %+v", res.Audit)
+	}
+}
+
+func TestAuditVCSError(t *testing.T) {
+	r := releases(t)
+	r.fake.Err = errFake
+
+	if _, err := r.run("audit"); !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+}
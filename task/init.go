@@ -2,13 +2,8 @@ package task
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"os"
-	"path/filepath"
 
-	"github.com/jwmwalrus/bumpy/internal/config"
-	"github.com/jwmwalrus/bumpy/version"
+	"github.com/jwmwalrus/bumpy/release"
 	"github.com/urfave/cli/v3"
 )
 
@@ -45,86 +40,44 @@ func Init() *cli.Command {
 				Name:  "npm-prefix",
 				Usage: "ubdirectory to find 'package.json', persistent as 'config.npmPrefixes'",
 			},
+			&cli.StringSliceFlag{
+				Name:  "cargo-prefix",
+				Usage: "Subdirectory to find 'Cargo.toml', persistent as 'config.cargoPrefixes'",
+			},
+			&cli.StringSliceFlag{
+				Name:  "maven-prefix",
+				Usage: "Subdirectory to find 'pom.xml', persistent as 'config.mavenPrefixes'",
+			},
+			&cli.StringSliceFlag{
+				Name:  "gradle-prefix",
+				Usage: "Subdirectory to find 'gradle.properties', persistent as 'config.gradlePrefixes'",
+			},
 		},
 	}
 }
 
 func initAction(ctx context.Context, c *cli.Command) (err error) {
-	cfg, configCreated, err := config.LoadOrCreate()
-	if err != nil {
-		return
-	}
-
-	if !configCreated {
-		fmt.Printf("Config file already existed!\n")
-	}
-
-	versionFile := filepath.Join(cfg.VersionPrefix, version.Filename)
-	_, err = os.Stat(versionFile)
-	if !os.IsNotExist(err) {
-		if !configCreated {
-			err = errors.New("Repository is already initialized, isn't it?")
-			return
-		}
-	}
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
 
-	if c.Bool("no-fetch") {
-		if !configCreated {
-			fmt.Printf("Overriding `noFetch` in config file")
-		}
-		cfg.NoFetch = c.Bool("no-fetch")
-	}
-	if c.Bool("no-commit") {
-		if !configCreated {
-			fmt.Printf("Overriding `noCommit` in config file")
-		}
-		cfg.NoCommit = c.Bool("no-commit")
-	}
-	if c.String("version-prefix") != "" {
-		if !configCreated {
-			fmt.Printf("Overriding `prefix` in config file")
-		}
-		cfg.VersionPrefix = c.String("version-prefix")
-	}
-	if len(c.StringSlice("npm-prefix")) > 0 {
-		if !configCreated {
-			fmt.Printf("Overriding `npmPrefix` in config file")
-		}
-		cfg.NPMPrefixes = c.StringSlice("npm-prefix")
-	}
-
-	if err = cfg.Save(); err != nil {
-		return
-	}
-
-	v := version.Version{}
-	tag, err := cfg.Git.LatestTag(cfg.NoFetch)
+	res, err := release.Init(ctx, release.InitOptions{
+		Persist:        c.Bool("persist"),
+		NoFetch:        c.Bool("no-fetch"),
+		NoCommit:       c.Bool("no-commit"),
+		VersionPrefix:  c.String("version-prefix"),
+		NPMPrefixes:    c.StringSlice("npm-prefix"),
+		CargoPrefixes:  c.StringSlice("cargo-prefix"),
+		MavenPrefixes:  c.StringSlice("maven-prefix"),
+		GradlePrefixes: c.StringSlice("gradle-prefix"),
+		Log:            o.msg,
+	})
 	if err != nil {
-		v = version.New()
-	} else {
-		if err = v.Parse(tag); err != nil {
-			return
-		}
-	}
-
-	if err = v.SaveTo(cfg.VersionPrefix); err != nil {
 		return
 	}
 
-	if c.Bool("persist") {
-		fmt.Printf("\nCommitting files...\n")
-		err = cfg.Git.CommitFiles(
-			[]string{
-				filepath.Join(".", config.Filename),
-				versionFile,
-			},
-			"Init version",
-		)
-		if err != nil {
-			return
-		}
-	}
-
-	fmt.Printf("Done!\n")
+	o.SetVersion(res.Version)
+	o.res.NewVersion = res.Version.String()
+	o.res.Commit = res.Commit
+	o.AddFiles(res.Files...)
 	return
 }
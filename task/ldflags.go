@@ -0,0 +1,169 @@
+package task
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	defaultVersionVar = "main.Version"
+	defaultCommitVar  = "main.Commit"
+	defaultDateVar    = "main.Date"
+)
+
+// LDFlags prints the linker flags to inject version metadata.
+func LDFlags() *cli.Command {
+	return &cli.Command{
+		Name:            "ldflags",
+		Category:        "Informational",
+		Usage:           "Display linker flags",
+		UsageText:       "ldflags [--version-var VAR] [--commit-var VAR] [--date-var VAR] ...",
+		Description:     "Displays the `-X` linker flags that inject the version, commit and date into a Go binary, e.g.: go build -ldflags \"$(bumpy ldflags)\". The date is taken from SOURCE_DATE_EPOCH, if set, or from the HEAD commit otherwise",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          ldflagsAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "version-var",
+				Usage: "Fully-qualified `VAR` to set to the version, overriding 'config.ldflags.version'",
+			},
+			&cli.StringFlag{
+				Name:  "commit-var",
+				Usage: "Fully-qualified `VAR` to set to the HEAD commit, overriding 'config.ldflags.commit'",
+			},
+			&cli.StringFlag{
+				Name:  "date-var",
+				Usage: "Fully-qualified `VAR` to set to the build date, overriding 'config.ldflags.date'",
+			},
+			&cli.BoolFlag{
+				Name:  "no-prefix",
+				Usage: "Remove v from the beginning of the version string",
+			},
+		},
+	}
+}
+
+func ldflagsAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+
+	v := version.Version{}
+	if err = v.LoadFrom(cfg.Path(cfg.VersionPrefix)); err != nil {
+		return
+	}
+
+	vars := config.LDFlags{
+		Version: defaultVersionVar,
+		Commit:  defaultCommitVar,
+		Date:    defaultDateVar,
+	}
+	if cfg.LDFlags != nil {
+		if cfg.LDFlags.Version != "" {
+			vars.Version = cfg.LDFlags.Version
+		}
+		if cfg.LDFlags.Commit != "" {
+			vars.Commit = cfg.LDFlags.Commit
+		}
+		if cfg.LDFlags.Date != "" {
+			vars.Date = cfg.LDFlags.Date
+		}
+	}
+
+	if c.IsSet("version-var") {
+		vars.Version = c.String("version-var")
+	}
+	if c.IsSet("commit-var") {
+		vars.Commit = c.String("commit-var")
+	}
+	if c.IsSet("date-var") {
+		vars.Date = c.String("date-var")
+	}
+	for _, name := range []*string{&vars.Version, &vars.Commit, &vars.Date} {
+		if *name == config.LDFlagsDisabled {
+			*name = ""
+		}
+	}
+
+	var commit string
+	var commitTime time.Time
+	if vars.Commit != "" || vars.Date != "" {
+		if !cfg.HasVCS() {
+			o.Warnf("Not in a repository, omitting the commit")
+			vars.Commit = ""
+			commitTime = time.Now()
+		} else if commit, commitTime, err = headCommit(cfg); err != nil {
+			return
+		}
+	}
+
+	flags := []string{}
+
+	if vars.Version != "" {
+		str := v.String()
+		if c.Bool("no-prefix") {
+			str = v.StringNoV()
+		}
+		flags = append(flags, "-X "+vars.Version+"="+str)
+	}
+
+	if vars.Commit != "" {
+		flags = append(flags, "-X "+vars.Commit+"="+commit)
+	}
+
+	if vars.Date != "" {
+		var date time.Time
+		if date, err = buildDate(commitTime); err != nil {
+			return
+		}
+		flags = append(flags, "-X "+vars.Date+"="+date.UTC().Format(time.RFC3339))
+	}
+
+	o.SetVersion(v)
+	o.res.Commit = commit
+	o.res.LDFlags = flags
+	o.Println(strings.Join(flags, " "))
+	return
+}
+
+func headCommit(cfg *config.Config) (hash string, t time.Time, err error) {
+	c, err := cfg.VCS.Head()
+	if err != nil {
+		return
+	}
+
+	hash = c.Hash
+	t = c.Time
+	return
+}
+
+// buildDate honors SOURCE_DATE_EPOCH, for reproducible builds
+func buildDate(fallback time.Time) (t time.Time, err error) {
+	epoch := os.Getenv("SOURCE_DATE_EPOCH")
+	if epoch == "" {
+		t = fallback
+		return
+	}
+
+	secs, err := strconv.ParseInt(epoch, 10, 64)
+	if err != nil {
+		err = fmt.Errorf("Invalid SOURCE_DATE_EPOCH: %w", err)
+		return
+	}
+
+	t = time.Unix(secs, 0)
+	return
+}
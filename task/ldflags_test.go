@@ -0,0 +1,67 @@
+package task
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestLDFlags(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+
+	c, _ := r.fake.Head()
+	res := r.mustRun("ldflags")
+	want := []string{
+		"-X main.Version=v1.0.0",
+		"-X main.Commit=" + c.Hash,
+		"-X main.Date=" + c.Time.UTC().Format(time.RFC3339),
+	}
+	if !slices.Equal(res.LDFlags, want) {
+		t.Errorf("got %v, want %v", res.LDFlags, want)
+	}
+
+	r.mustRun("config", "--ldflags-version-var", "pkg/build.Version")
+	res = r.mustRun("ldflags", "--no-prefix", "--commit-var", "pkg/build.Commit", "--date-var", "")
+	want = []string{
+		"-X pkg/build.Version=1.0.0",
+		"-X pkg/build.Commit=" + c.Hash,
+	}
+	if !slices.Equal(res.LDFlags, want) {
+		t.Errorf("got %v, want %v", res.LDFlags, want)
+	}
+
+	// the config disables a variable, unless a flag sets it back
+	r.mustRun("config", "--ldflags-version-var", "", "--ldflags-commit-var", "-")
+	res = r.mustRun("ldflags", "--date-var", "-")
+	if want := []string{"-X main.Version=v1.0.0"}; !slices.Equal(res.LDFlags, want) {
+		t.Errorf("got %v, want %v", res.LDFlags, want)
+	}
+	res = r.mustRun("ldflags", "--commit-var", "main.Commit", "--date-var", "-")
+	want = []string{"-X main.Version=v1.0.0", "-X main.Commit=" + c.Hash}
+	if !slices.Equal(res.LDFlags, want) {
+		t.Errorf("got %v, want %v", res.LDFlags, want)
+	}
+
+	t.Setenv("SOURCE_DATE_EPOCH", "0")
+	res = r.mustRun("ldflags", "--version-var", "", "--commit-var", "")
+	if want := []string{"-X main.Date=1970-01-01T00:00:00Z"}; !slices.Equal(res.LDFlags, want) {
+		t.Errorf("got %v, want %v", res.LDFlags, want)
+	}
+}
+
+func TestLDFlagsErrors(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+
+	t.Setenv("SOURCE_DATE_EPOCH", "yesterday")
+	if _, err := r.run("ldflags"); err == nil {
+		t.Error("expected error for an invalid SOURCE_DATE_EPOCH")
+	}
+
+	r.fake.Err = errFake
+	if _, err := r.run("ldflags"); !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+}
@@ -0,0 +1,86 @@
+package task
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/release"
+	"github.com/urfave/cli/v3"
+)
+
+// Next displays the version that bump would produce.
+func Next() *cli.Command {
+	return &cli.Command{
+		Name:            "next",
+		Aliases:         []string{"n"},
+		Category:        "Informational",
+		Usage:           "Display next version",
+		UsageText:       "next [--major|--minor|--patch|--auto] [--pre PRE] [--build BUILD] [--short|--format TEMPLATE] ...",
+		Description:     "Displays the version and tag that the bump command would produce for the same options, without modifying any file",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          nextAction,
+		Flags: append(
+			bumpFlags(),
+			&cli.BoolFlag{
+				Name:    "short",
+				Aliases: []string{"s"},
+				Usage:   "Display only the version",
+			},
+			&cli.BoolFlag{
+				Name:  "no-prefix",
+				Usage: "Remove v from the beginning of the version string",
+			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "Format the next version according to the given Go `TEMPLATE`, as in the version command",
+			},
+		),
+	}
+}
+
+func nextAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	opts, err := bumpOptions(c)
+	if err != nil {
+		return
+	}
+
+	current, v, err := release.Next(ctx, opts)
+	if err != nil {
+		return
+	}
+
+	o.res.OldVersion = current.String()
+	o.res.NewVersion = v.String()
+	o.res.Tag = v.String()
+
+	str := v.String()
+	if c.Bool("no-prefix") {
+		str = v.StringNoV()
+	}
+
+	if c.String("format") != "" {
+		var cfg *config.Config
+		if cfg, err = config.Load(); err != nil {
+			return
+		}
+
+		if str, err = formatVersion(cfg, v, c.String("format")); err != nil {
+			return
+		}
+		o.res.Formatted = str
+		o.Println(str)
+	} else if c.Bool("short") {
+		o.Println(str)
+	} else {
+		o.Println(fmt.Sprintf("\nNext version: %v\nNext tag: %v", str, v.String()))
+	}
+
+	return
+}
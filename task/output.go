@@ -0,0 +1,158 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	// OutputText is the default, human-readable output format
+	OutputText = "text"
+
+	// OutputJSON is the machine-readable output format
+	OutputJSON = "json"
+)
+
+// result is the machine-readable outcome of a command
+type result struct {
+	Command    string           `json:"command"`
+	Version    string           `json:"version,omitempty"`
+	Details    *version.Version `json:"details,omitempty"`
+	OldVersion string           `json:"oldVersion,omitempty"`
+	NewVersion string           `json:"newVersion,omitempty"`
+	Formatted  string           `json:"formatted,omitempty"`
+	Files      []string         `json:"files,omitempty"`
+	Commit     string           `json:"commit,omitempty"`
+	Tag        string           `json:"tag,omitempty"`
+	LDFlags    []string         `json:"ldflags,omitempty"`
+	Checks     []statusCheck    `json:"checks,omitempty"`
+	History    []historyEntry   `json:"history,omitempty"`
+	Release    *releaseDetails  `json:"release,omitempty"`
+	Audit      []auditFinding   `json:"audit,omitempty"`
+	Config     *config.Config   `json:"config,omitempty"`
+	Settings   []config.Setting `json:"settings,omitempty"`
+	Warnings   []string         `json:"warnings,omitempty"`
+	Error      string           `json:"error,omitempty"`
+}
+
+// output sends human-readable messages to stdout, or to stderr when the JSON
+// output is requested, in which case the result is written to stdout
+type output struct {
+	json bool
+	out  io.Writer
+	msg  io.Writer
+	res  result
+}
+
+// OutputFlag returns the global flag selecting the output format.
+func OutputFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:    "output",
+		Aliases: []string{"o"},
+		Value:   OutputText,
+		Usage:   "Output `FORMAT`, either 'text' or 'json'",
+		Validator: func(s string) error {
+			if s != OutputText && s != OutputJSON {
+				return fmt.Errorf("Unsupported output format: %v", s)
+			}
+			return nil
+		},
+	}
+}
+
+func newOutput(ctx context.Context, c *cli.Command) *output {
+	o := &output{
+		json: c.Root().String("output") == OutputJSON,
+		out:  c.Root().Writer,
+		res:  result{Command: c.Name},
+	}
+
+	if o.out == nil {
+		o.out = os.Stdout
+	}
+
+	o.msg = o.out
+	if o.json {
+		o.msg = c.Root().ErrWriter
+		if o.msg == nil {
+			o.msg = os.Stderr
+		}
+	}
+
+	return o
+}
+
+// Printf prints a human-readable message
+func (o *output) Printf(format string, a ...any) {
+	fmt.Fprintf(o.msg, format, a...)
+}
+
+// Println prints the main human-readable output of informational commands,
+// which is omitted when the JSON output is requested
+func (o *output) Println(a ...any) {
+	if !o.json {
+		fmt.Fprintln(o.out, a...)
+	}
+}
+
+// Warnf prints a warning and records it in the result
+func (o *output) Warnf(format string, a ...any) {
+	msg := strings.TrimSpace(fmt.Sprintf(format, a...))
+	o.res.Warnings = append(o.res.Warnings, msg)
+	fmt.Fprintf(o.msg, "WARNING, %v\n", msg)
+}
+
+// SetVersion records the given version as the one the command worked with
+func (o *output) SetVersion(v version.Version) {
+	o.res.Version = v.String()
+	o.res.Details = &v
+}
+
+// check records an item of the status report
+func (o *output) check(name, status, format string, a ...any) {
+	o.res.Checks = append(o.res.Checks, statusCheck{
+		Name:    name,
+		Status:  status,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// audit records an item of the audit report
+func (o *output) audit(tag, check, status, format string, a ...any) {
+	o.res.Audit = append(o.res.Audit, auditFinding{
+		Tag:     tag,
+		Check:   check,
+		Status:  status,
+		Message: fmt.Sprintf(format, a...),
+	})
+}
+
+// AddFiles records the given files as changed
+func (o *output) AddFiles(files ...string) {
+	o.res.Files = append(o.res.Files, files...)
+}
+
+// Flush writes the result, when the JSON output is requested
+func (o *output) Flush(err *error) {
+	if !o.json {
+		return
+	}
+
+	if *err != nil {
+		o.res.Error = (*err).Error()
+	}
+
+	enc := json.NewEncoder(o.out)
+	enc.SetIndent("", "  ")
+	if encErr := enc.Encode(o.res); encErr != nil && *err == nil {
+		*err = encErr
+	}
+}
@@ -0,0 +1,209 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/jwmwalrus/bumpy/internal/changelog"
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+// releaseDetails describes a single release
+type releaseDetails struct {
+	Tag         string       `json:"tag"`
+	Version     string       `json:"version"`
+	Component   string       `json:"component,omitempty"`
+	Commit      string       `json:"commit"`
+	Annotated   bool         `json:"annotated"`
+	Tagger      string       `json:"tagger,omitempty"`
+	Date        time.Time    `json:"date"`
+	Message     string       `json:"message,omitempty"`
+	VersionFile string       `json:"versionFile,omitempty"`
+	ChangeLog   string       `json:"changelog,omitempty"`
+	Previous    string       `json:"previous,omitempty"`
+	Commits     []vcs.Commit `json:"commits"`
+}
+
+// Show displays the details of a release.
+func Show() *cli.Command {
+	return &cli.Command{
+		Name:            "show",
+		Category:        "Informational",
+		Usage:           "Display release details",
+		UsageText:       "show [--component NAME] [--changelog-name FILENAME] VERSION",
+		Description:     "Displays the full annotation, tagger, date and target commit of the given version tag, along with the content of the version file at that tag, the matching ChangeLog section and the commits since the previous release",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          showAction,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:  "component",
+				Usage: "Look for tags of the given `COMPONENT`, named like 'COMPONENT/v1.2.3'",
+			},
+			&cli.StringFlag{
+				Name:  "changelog-name",
+				Usage: "Name (including extension) of the ChangeLog file",
+			},
+		},
+	}
+}
+
+func showAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	if c.Args().Len() != 1 {
+		err = errors.New("Exactly one version must be provided")
+		return
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return
+	}
+
+	tags, err := versionTags(cfg, c.String("component"))
+	if err != nil {
+		return
+	}
+
+	idx, err := findRelease(tags, c.Args().First())
+	if err != nil {
+		return
+	}
+
+	t := tags[idx]
+	o.SetVersion(t.Version)
+	o.res.Tag = t.Name
+
+	rel := &releaseDetails{
+		Tag:       t.Name,
+		Version:   t.Version.String(),
+		Component: t.Component,
+		Commit:    t.Commit,
+		Annotated: t.Annotated,
+		Tagger:    t.Tagger,
+		Date:      t.Date,
+		Message:   t.Message,
+	}
+	o.res.Release = rel
+
+	versionFile := cfg.RepoPath(filepath.Join(cfg.VersionPrefix, version.Filename))
+	if bv, vErr := cfg.VCS.Show(t.Name, versionFile); vErr != nil {
+		o.Warnf("Unable to read %v at %v: %v", versionFile, t.Name, vErr)
+	} else {
+		rel.VersionFile = strings.TrimSpace(string(bv))
+	}
+
+	if filename, clErr := changelog.Resolve(io.Discard, cfg.Dir, argPath(c.String("changelog-name"))); clErr == nil {
+		bv, clErr := cfg.VCS.Show(t.Name, cfg.RepoPath(filename))
+		if clErr != nil {
+			bv, clErr = os.ReadFile(cfg.Path(filename))
+		}
+		if clErr == nil {
+			rel.ChangeLog, _ = changelog.Section(bv, t.Version)
+		}
+	}
+
+	if prev := previousRelease(tags, idx); prev >= 0 {
+		rel.Previous = tags[prev].Name
+	}
+
+	if rel.Commits, err = cfg.VCS.Log(rel.Previous, t.Name); err != nil {
+		return
+	}
+
+	o.Println(formatRelease(rel))
+	return
+}
+
+// findRelease returns the index of the tag matching the given tag name or
+// version
+func findRelease(tags []versionTag, name string) (idx int, err error) {
+	for i, t := range tags {
+		if t.Name == name {
+			return i, nil
+		}
+	}
+
+	var v version.Version
+	_, vname := splitComponent(name)
+	if err = v.Parse(vname); err != nil {
+		err = fmt.Errorf("Invalid version: %v", name)
+		return
+	}
+
+	for i, t := range tags {
+		if t.Version.StringNoV() == v.StringNoV() {
+			return i, nil
+		}
+	}
+
+	err = fmt.Errorf("No tag found for version %v", name)
+	return
+}
+
+// previousRelease returns the index of the release preceding the one at idx
+// or -1 if there is none. Prereleases are skipped for a final release
+func previousRelease(tags []versionTag, idx int) int {
+	for i := idx + 1; i < len(tags); i++ {
+		if tags[i].Version.Compare(tags[idx].Version) == 0 {
+			continue
+		}
+		if tags[idx].Version.Pre == "" && tags[i].Version.Pre != "" {
+			continue
+		}
+		return i
+	}
+	return -1
+}
+
+func formatRelease(rel *releaseDetails) string {
+	var sb strings.Builder
+
+	fmt.Fprintf(&sb, "Tag:      %v\n", rel.Tag)
+	fmt.Fprintf(&sb, "Version:  %v\n", rel.Version)
+	fmt.Fprintf(&sb, "Commit:   %v\n", rel.Commit)
+	if rel.Annotated {
+		fmt.Fprintf(&sb, "Tagger:   %v\n", rel.Tagger)
+	} else {
+		fmt.Fprintf(&sb, "Tagger:   (lightweight tag)\n")
+	}
+	fmt.Fprintf(&sb, "Date:     %v\n", rel.Date.Format(time.RFC1123Z))
+
+	if rel.Previous != "" {
+		fmt.Fprintf(&sb, "Previous: %v\n", rel.Previous)
+	}
+
+	section := func(title, body string) {
+		fmt.Fprintf(&sb, "\n%v:\n", title)
+		if body == "" {
+			body = "(none)"
+		}
+		for _, l := range strings.Split(body, "\n") {
+			fmt.Fprintln(&sb, strings.TrimRight("    "+l, " "))
+		}
+	}
+
+	section("Annotation", rel.Message)
+	section("Version file", rel.VersionFile)
+	section("ChangeLog", rel.ChangeLog)
+
+	var commits []string
+	for _, cm := range rel.Commits {
+		commits = append(commits, shortHash(cm.Hash)+" "+cm.Subject)
+	}
+	section(fmt.Sprintf("Commits (%v)", len(rel.Commits)), strings.Join(commits, "\n"))
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
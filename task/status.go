@@ -0,0 +1,236 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/jwmwalrus/bumpy/internal/changelog"
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/generate"
+	"github.com/jwmwalrus/bumpy/internal/updater"
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+// Check states
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+)
+
+// statusCheck is a single item of the status report
+type statusCheck struct {
+	Name    string `json:"name"`
+	Status  string `json:"status"`
+	Message string `json:"message"`
+}
+
+// Status reports the versioning health of the repository.
+func Status() *cli.Command {
+	return &cli.Command{
+		Name:            "status",
+		Aliases:         []string{"st", "doctor"},
+		Category:        "Informational",
+		Usage:           "Display versioning status",
+		UsageText:       "status",
+		Description:     "Reports the versioning health of the repository: config, version file, latest tag, commits since the latest tag, worktree state, ChangeLog entry and the version of every configured updater file. Exits with a non-zero status if something is inconsistent",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Action:          statusAction,
+		Flags:           []cli.Flag{},
+	}
+}
+
+func statusAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
+	defer func() {
+		for _, chk := range o.res.Checks {
+			o.Println(fmt.Sprintf("[%-4s] %-10s %v", chk.Status, chk.Name, chk.Message))
+		}
+
+		for _, chk := range o.res.Checks {
+			if chk.Status == checkFail && err == nil {
+				err = cli.Exit("Versioning is inconsistent", 1)
+				break
+			}
+		}
+	}()
+
+	cfg, cfgErr := config.Load()
+	if cfgErr != nil {
+		o.check("config", checkFail, "%v: %v", config.Filename, cfgErr)
+		return
+	}
+	o.check("config", checkOK, "%v", cfg.File)
+	if cfg.Profile != nil {
+		o.check("profile", checkOK, "%v, matching branch %v", cfg.Profile.Branch, cfg.Branch)
+	}
+
+	versionFile := filepath.Join(cfg.VersionPrefix, version.Filename)
+	var v version.Version
+	if vErr := v.LoadFrom(cfg.Path(cfg.VersionPrefix)); vErr != nil {
+		o.check("version", checkFail, "%v: %v", versionFile, vErr)
+		return
+	}
+	o.SetVersion(v)
+	o.check("version", checkOK, "%v (%v)", v.String(), versionFile)
+
+	if cfg.HasVCS() {
+		statusTag(o, cfg, v)
+		statusWorktree(o, cfg)
+	} else {
+		o.check("vcs", checkWarn, "not in a repository")
+	}
+	statusChangeLog(o, cfg, v)
+	statusUpdaters(o, cfg, v)
+
+	return
+}
+
+func statusTag(o *output, cfg *config.Config, v version.Version) {
+	tag, err := cfg.VCS.LatestTag(cfg.NoFetch)
+	if err != nil {
+		o.check("tag", checkWarn, "unable to obtain latest tag")
+		tag = ""
+	} else {
+		o.res.Tag = tag
+
+		var tv version.Version
+		if err = tv.Parse(tag); err != nil {
+			o.check("tag", checkFail, "%v is not a valid version", tag)
+		} else {
+			switch v.Compare(tv) {
+			case 0:
+				o.check("tag", checkOK, "%v, in sync", tag)
+			case 1:
+				o.check("tag", checkWarn, "%v, version file is ahead (pending tag)", tag)
+			default:
+				o.check("tag", checkFail, "%v, version file is behind (please sync)", tag)
+			}
+		}
+	}
+
+	commits, err := cfg.VCS.Log(tag, "")
+	if err != nil {
+		o.check("commits", checkWarn, "unable to obtain commits: %v", err)
+		return
+	}
+
+	since := "in history"
+	if tag != "" {
+		since = "since " + tag
+	}
+
+	if len(commits) > 0 && tag != "" && v.String() == tag {
+		o.check("commits", checkWarn, "%v %v, version not bumped", len(commits), since)
+		return
+	}
+	o.check("commits", checkOK, "%v %v", len(commits), since)
+}
+
+func statusWorktree(o *output, cfg *config.Config) {
+	staged, unstaged, untracked, err := cfg.VCS.Status()
+	if err != nil {
+		o.check("worktree", checkWarn, "unable to obtain status: %v", err)
+		return
+	}
+
+	if n := len(staged) + len(unstaged) + len(untracked); n > 0 {
+		o.check("worktree", checkWarn, "dirty, %v staged, %v unstaged, %v untracked", len(staged), len(unstaged), len(untracked))
+		return
+	}
+	o.check("worktree", checkOK, "clean")
+}
+
+func statusChangeLog(o *output, cfg *config.Config, v version.Version) {
+	filename, err := changelog.Resolve(io.Discard, cfg.Dir, "")
+	if err != nil {
+		o.check("changelog", checkWarn, "no ChangeLog file found")
+		return
+	}
+
+	bv, err := os.ReadFile(cfg.Path(filename))
+	if err != nil {
+		o.check("changelog", checkWarn, "%v: %v", filename, err)
+		return
+	}
+
+	if _, ok := changelog.Section(bv, v); !ok {
+		o.check("changelog", checkWarn, "%v has no entry for %v", filename, v.StringNoV())
+		return
+	}
+	o.check("changelog", checkOK, "%v has an entry for %v", filename, v.StringNoV())
+}
+
+func statusUpdaters(o *output, cfg *config.Config, v version.Version) {
+	fsys := os.DirFS(cfg.Dir)
+
+	for _, r := range updaterReaders(cfg, v) {
+		for _, p := range r.prefixes {
+			list, err := r.read(fsys, filepath.ToSlash(filepath.Clean(p)))
+			if err != nil {
+				o.check(r.name, checkFail, "%v: %v", p, err)
+				continue
+			}
+
+			for _, fv := range list {
+				file := fv.Path
+				if fv.Name != "" {
+					file += " (" + fv.Name + ")"
+				}
+
+				if fv.Version != r.expected {
+					o.check(r.name, checkFail, "%v has %v, expected %v", file, fv.Version, r.expected)
+					continue
+				}
+				o.check(r.name, checkOK, "%v has %v", file, fv.Version)
+			}
+		}
+	}
+
+	for _, g := range cfg.Generate {
+		expected, err := generate.Render(cfg.Dir, g, v)
+		if err != nil {
+			o.check("generate", checkFail, "%v: %v", g.Target, err)
+			continue
+		}
+
+		actual, err := os.ReadFile(cfg.Path(g.Target))
+		if err != nil {
+			o.check("generate", checkFail, "%v: %v", g.Target, err)
+			continue
+		}
+
+		if !bytes.Equal(expected, actual) {
+			o.check("generate", checkFail, "%v is out of date", g.Target)
+			continue
+		}
+		o.check("generate", checkOK, "%v is up to date", g.Target)
+	}
+}
+
+// updaterReader reads the versions of the files of a configured updater
+type updaterReader struct {
+	name     string
+	prefixes []string
+	read     func(fs.FS, string) ([]updater.FileVersion, error)
+	expected string
+}
+
+func updaterReaders(cfg *config.Config, v version.Version) []updaterReader {
+	return []updaterReader{
+		{"npm", cfg.NPMPrefixes, updater.NPMVersions, v.StringNoV()},
+		{"cargo", cfg.CargoPrefixes, updater.CargoVersions, v.StringNoV()},
+		{"maven", cfg.MavenPrefixes, updater.MavenVersions, updater.MavenVersion(v)},
+		{"gradle", cfg.GradlePrefixes, updater.GradleVersions, updater.MavenVersion(v)},
+	}
+}
@@ -0,0 +1,64 @@
+package task
+
+import "testing"
+
+func TestStatus(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+	r.write("ChangeLog.md", "# ChangeLog\n\n## 1.0.0\n\nFirst release\n")
+	r.commit("Add ChangeLog", map[string]string{"ChangeLog.md": r.read("ChangeLog.md")})
+
+	res := r.mustRun("status")
+	want := map[string]string{
+		"config":    checkOK,
+		"version":   checkOK,
+		"tag":       checkOK,
+		"commits":   checkWarn,
+		"worktree":  checkOK,
+		"changelog": checkOK,
+	}
+	for name, status := range want {
+		if got := checkOf(res, name); got != status {
+			t.Errorf("%v: status = %q, want %q", name, got, status)
+		}
+	}
+}
+
+func TestStatusDirtyWorktree(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+	r.fake.Staged = []string{"a.go"}
+	r.fake.Untracked = []string{"b.go"}
+
+	res := r.mustRun("status")
+	if got := checkOf(res, "worktree"); got != checkWarn {
+		t.Errorf("worktree: status = %q, want %q", got, checkWarn)
+	}
+}
+
+func TestStatusBehindTag(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+	r.tag("v1.1.0")
+
+	res, err := r.run("status")
+	if exitCode(err) != 1 {
+		t.Errorf("got %v, want exit code 1", err)
+	}
+	if got := checkOf(res, "tag"); got != checkFail {
+		t.Errorf("tag: status = %q, want %q", got, checkFail)
+	}
+}
+
+func TestStatusVCSError(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+	r.fake.Err = errFake
+
+	res := r.mustRun("status")
+	for _, name := range []string{"tag", "commits", "worktree"} {
+		if got := checkOf(res, name); got != checkWarn {
+			t.Errorf("%v: status = %q, want %q", name, got, checkWarn)
+		}
+	}
+}
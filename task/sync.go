@@ -2,10 +2,8 @@ package task
 
 import (
 	"context"
-	"fmt"
 
-	"github.com/jwmwalrus/bumpy/internal/config"
-	"github.com/jwmwalrus/bumpy/version"
+	"github.com/jwmwalrus/bumpy/release"
 	"github.com/urfave/cli/v3"
 )
 
@@ -26,25 +24,19 @@ func Sync() *cli.Command {
 }
 
 func syncAction(ctx context.Context, c *cli.Command) (err error) {
-	cfg, err := config.Load()
-	if err != nil {
-		return
-	}
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
 
-	tag := ""
-	if tag, err = cfg.Git.LatestTag(cfg.NoFetch); err != nil {
-		return
+	res, err := release.Sync(ctx, release.SyncOptions{Log: o.msg})
+	if res.OldVersion != nil {
+		o.res.OldVersion = res.OldVersion.String()
 	}
-
-	v := version.Version{}
-	if err = v.Parse(tag); err != nil {
-		return
-	}
-
-	if err = v.SaveTo(cfg.VersionPrefix); err != nil {
+	if err != nil {
 		return
 	}
 
-	fmt.Printf("Done!\n")
+	o.res.NewVersion = res.NewVersion.String()
+	o.res.Tag = res.Tag
+	o.AddFiles(res.Files...)
 	return
 }
@@ -2,15 +2,8 @@ package task
 
 import (
 	"context"
-	"errors"
-	"fmt"
-	"io/ioutil"
-	"os"
-	"strings"
 
-	"github.com/jwmwalrus/bumpy/internal/config"
-	"github.com/jwmwalrus/bumpy/version"
-	"github.com/russross/blackfriday/v2"
+	"github.com/jwmwalrus/bumpy/release"
 	"github.com/urfave/cli/v3"
 )
 
@@ -41,121 +34,21 @@ func Tag() *cli.Command {
 }
 
 func tagAction(ctx context.Context, c *cli.Command) (err error) {
-	cfg, err := config.Load()
-	if err != nil {
-		return
-	}
-
-	fmt.Printf("\nLoading current version file...\n")
-	v := version.Version{}
-	if err = v.LoadFrom(cfg.VersionPrefix); err != nil {
-		return
-	}
-
-	fmt.Printf("\tVersion to use as tag: %v\n", v.String())
-
-	msg := c.String("tag-message")
-	if msg == "" {
-		filename := c.String("changelog-name")
-
-		if filename, err = resolveChangeLogFilename(filename); err != nil {
-			return
-		}
-
-		fmt.Printf("\nLoading %v...\n", filename)
-
-		msg = getChangeLogMessage(v, filename)
-		msg = strings.TrimSuffix(msg, "\n")
-
-		fmt.Printf("\nCommitting ChangeLog file...\n")
-		err = cfg.Git.CommitFiles([]string{filename}, "Update ChangeLog")
-		if err != nil {
-			return
-		}
-	}
-
-	if msg == "" {
-		msg = "New version"
-	}
-
-	fmt.Printf("\nCreating annotated tag with `%s` as message\n", msg)
-	err = cfg.Git.NewTag(v.String(), msg)
-	if err != nil {
-		return
-	}
-
-	fmt.Printf("\nDone!\n")
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
 
-	return
-}
-
-func getChangeLogMessage(v version.Version, filename string) (msg string) {
-	file, err := os.Open(filename)
-	if err != nil {
-		return
-	}
-	defer file.Close()
-
-	var bv []byte
-	bv, err = ioutil.ReadAll(file)
+	res, err := release.Tag(ctx, release.TagOptions{
+		ChangeLog: argPath(c.String("changelog-name")),
+		Message:   c.String("tag-message"),
+		Log:       o.msg,
+	})
+	o.res.Commit = res.Commit
+	o.AddFiles(res.Files...)
 	if err != nil {
 		return
 	}
 
-	fmt.Printf("\nParsing %v...\n", filename)
-	md := blackfriday.New()
-
-	var node *blackfriday.Node
-	if node = md.Parse(bv); node == nil {
-		err = errors.New("Error parsing Markdown")
-		return
-	}
-
-	node.Walk(func(n *blackfriday.Node, e bool) blackfriday.WalkStatus {
-		if e && n.Type == blackfriday.Heading && n.FirstChild != nil && n.Next != nil && n.Next.Type == blackfriday.Paragraph {
-			if strings.Contains(string(n.FirstChild.Literal), v.StringNoV()) {
-				msg = string(n.Next.FirstChild.Literal)
-				return blackfriday.Terminate
-			}
-		}
-		return blackfriday.GoToNext
-	})
-
+	o.SetVersion(res.Version)
+	o.res.Tag = res.Tag
 	return
 }
-
-func resolveChangeLogFilename(filename string) (string, error) {
-	if filename == "" {
-		fmt.Printf("\nLooking for a ChangeLog file\n")
-		commonNames := []string{
-			"CHANGELOG.md",
-			"ChangeLog.md",
-			"Changelog.md",
-			"changelog.md",
-			"HISTORY.md",
-			"History.md",
-			"history.md",
-			"NEWS.md",
-			"News.md",
-			"news.md",
-			"RELEASES.md",
-			"Releases.md",
-			"releases.md",
-		}
-
-		for _, fn := range commonNames {
-			_, err := os.Stat(fn)
-			if os.IsNotExist(err) {
-				continue
-			}
-			fmt.Printf("\tFound filename: %v\n", fn)
-			filename = fn
-			break
-		}
-
-		if filename == "" {
-			return filename, fmt.Errorf("\tUnable to find ChangeLog")
-		}
-	}
-	return filename, nil
-}
@@ -0,0 +1,62 @@
+package task
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestTag(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+	r.mustRun("bump", "--minor")
+	r.write("ChangeLog.md", "# ChangeLog\n\n## 0.2.0\n\nSomething new\n")
+
+	res := r.mustRun("tag")
+	if res.Tag != "v0.2.0" {
+		t.Errorf("tag = %v, want v0.2.0", res.Tag)
+	}
+	if got := r.fake.Commits[len(r.fake.Commits)-1].Subject; got != "Update ChangeLog" {
+		t.Errorf("last commit = %q, want %q", got, "Update ChangeLog")
+	}
+
+	tags, _ := r.fake.Tags()
+	if len(tags) != 1 || tags[0].Commit != r.head() {
+		t.Fatalf("unexpected tags: %+v", tags)
+	}
+	if msg := tags[0].Message; msg != "Something new" {
+		t.Errorf("tag message = %q, want the ChangeLog entry", msg)
+	}
+
+	if _, err := r.run("tag"); err == nil {
+		t.Error("expected error tagging an existing version")
+	}
+}
+
+func TestTagMessage(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	r.mustRun("tag", "--tag-message", "First release")
+	if len(r.fake.Commits) != 1 {
+		t.Errorf("committed despite --tag-message: %+v", r.fake.Commits)
+	}
+
+	tags, _ := r.fake.Tags()
+	if len(tags) != 1 || tags[0].Name != "v0.1.0" || tags[0].Message != "First release" {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+}
+
+func TestTagErrors(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	if _, err := r.run("tag"); err == nil {
+		t.Error("expected error without a ChangeLog")
+	}
+
+	r.fake.Err = errFake
+	if _, err := r.run("tag", "--tag-message", "Release"); !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+}
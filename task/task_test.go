@@ -0,0 +1,357 @@
+package task
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jwmwalrus/bumpy/internal/config"
+	"github.com/jwmwalrus/bumpy/internal/vcs"
+	"github.com/jwmwalrus/bumpy/release"
+	"github.com/urfave/cli/v3"
+)
+
+// fakeRepo is a repository backed by a vcs.Fake, the commands are run on
+type fakeRepo struct {
+	t    *testing.T
+	dir  string
+	fake *vcs.Fake
+}
+
+// newFakeRepo returns an empty repository, injected through config.OpenVCS,
+// isolated from the user-level config and the BUMPY_* environment variables
+func newFakeRepo(t *testing.T) *fakeRepo {
+	t.Helper()
+
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &fakeRepo{t: t, dir: dir, fake: vcs.NewFake(dir)}
+	r.fake.CurrentBranch = "main"
+
+	clock := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	r.fake.Now = func() time.Time {
+		clock = clock.Add(time.Hour)
+		return clock
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	open := config.OpenVCS
+	config.OpenVCS = func(string, string) (vcs.VCS, error) { return r.fake, nil }
+	t.Cleanup(func() {
+		config.OpenVCS = open
+		os.Chdir(wd)
+	})
+
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("SOURCE_DATE_EPOCH", "")
+	t.Setenv("GITHUB_ACTIONS", "")
+	for _, kv := range os.Environ() {
+		if name, _, _ := strings.Cut(kv, "="); strings.HasPrefix(name, config.EnvPrefix) {
+			t.Setenv(name, "")
+		}
+	}
+
+	return r
+}
+
+// run runs the given command line with the JSON output, at the top-level
+// directory, and returns the decoded result
+func (r *fakeRepo) run(args ...string) (result, error) {
+	r.t.Helper()
+	return r.runAt(r.dir, args...)
+}
+
+// runAt is like run, but at the given directory
+func (r *fakeRepo) runAt(dir string, args ...string) (res result, err error) {
+	r.t.Helper()
+
+	var out, msg bytes.Buffer
+	cmd := &cli.Command{
+		Name:   "bumpy",
+		Before: Before,
+		Flags:  []cli.Flag{OutputFlag(), DirFlag()},
+		Commands: []*cli.Command{
+			Init(),
+			Bump(),
+			Sync(),
+			Tag(),
+			Version(),
+			Next(),
+			Status(),
+			History(),
+			Show(),
+			Audit(),
+			Check(),
+			LDFlags(),
+			Config(),
+		},
+		Writer:         &out,
+		ErrWriter:      &msg,
+		ExitErrHandler: func(context.Context, *cli.Command, error) {},
+	}
+
+	err = cmd.Run(context.Background(), append([]string{"bumpy", "-C", dir, "-o", "json"}, args...))
+
+	if out.Len() > 0 {
+		if jErr := json.Unmarshal(out.Bytes(), &res); jErr != nil {
+			r.t.Fatalf("%v: invalid output: %v\n%s", args, jErr, out.Bytes())
+		}
+	}
+	return
+}
+
+// mustRun is like run, but fails the test on error
+func (r *fakeRepo) mustRun(args ...string) result {
+	r.t.Helper()
+
+	res, err := r.run(args...)
+	if err != nil {
+		r.t.Fatalf("%v: %v", args, err)
+	}
+	return res
+}
+
+// write writes the given file, relative to the repository
+func (r *fakeRepo) write(name, content string) {
+	r.t.Helper()
+
+	p := filepath.Join(r.dir, name)
+	if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+		r.t.Fatal(err)
+	}
+	if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// read reads the given file, relative to the repository
+func (r *fakeRepo) read(name string) string {
+	r.t.Helper()
+
+	bv, err := os.ReadFile(filepath.Join(r.dir, name))
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return string(bv)
+}
+
+// commit writes and commits the given files, and returns the new HEAD
+func (r *fakeRepo) commit(msg string, files map[string]string) string {
+	r.t.Helper()
+
+	var list []string
+	for name, content := range files {
+		r.write(name, content)
+		list = append(list, filepath.Join(r.dir, name))
+	}
+
+	if err := r.fake.CommitFiles(list, msg); err != nil {
+		r.t.Fatal(err)
+	}
+	return r.head()
+}
+
+// tag tags the HEAD commit
+func (r *fakeRepo) tag(name string) {
+	r.t.Helper()
+
+	if err := r.fake.NewTag(name, "Release "+name); err != nil {
+		r.t.Fatal(err)
+	}
+}
+
+// head returns the hash of the HEAD commit
+func (r *fakeRepo) head() string {
+	r.t.Helper()
+
+	c, err := r.fake.Head()
+	if err != nil {
+		r.t.Fatal(err)
+	}
+	return c.Hash
+}
+
+// release initializes the repository and releases the given version, as
+// `bump` and `tag` would
+func (r *fakeRepo) release(v string) {
+	r.t.Helper()
+
+	if _, err := os.Stat(filepath.Join(r.dir, config.Filename)); err != nil {
+		r.mustRun("init", "--persist", "--no-fetch")
+	}
+	r.mustRun("bump", v)
+	r.mustRun("tag", "--tag-message", "Release "+v)
+}
+
+// checkOf returns the status of the named check, or an empty string if
+// missing
+func checkOf(res result, name string) string {
+	for _, chk := range res.Checks {
+		if chk.Name == name {
+			return chk.Status
+		}
+	}
+	return ""
+}
+
+// exitCode returns the exit code of the given error, or -1
+func exitCode(err error) int {
+	var ec cli.ExitCoder
+	if errors.As(err, &ec) {
+		return ec.ExitCode()
+	}
+	return -1
+}
+
+// errFake is set as vcs.Fake.Err to simulate a failing VCS
+var errFake = errors.New("fake failure")
+
+func TestInit(t *testing.T) {
+	r := newFakeRepo(t)
+
+	res := r.mustRun("init", "--persist")
+	if res.Version != "v0.1.0" {
+		t.Errorf("version = %v, want v0.1.0", res.Version)
+	}
+	if len(r.fake.Commits) != 1 || r.fake.Commits[0].Subject != "Init version" {
+		t.Fatalf("unexpected commits: %+v", r.fake.Commits)
+	}
+	for _, f := range []string{config.Filename, "version.json"} {
+		if _, ok := r.fake.Commits[0].Files[f]; !ok {
+			t.Errorf("%v not committed", f)
+		}
+	}
+
+	if _, err := r.run("init"); !errors.Is(err, release.ErrAlreadyInitialized) {
+		t.Errorf("second init: got %v, want already initialized", err)
+	}
+}
+
+func TestInitFromLatestTag(t *testing.T) {
+	r := newFakeRepo(t)
+	r.commit("Initial commit", map[string]string{"main.go": "package main\n"})
+	r.tag("v1.2.3")
+
+	res := r.mustRun("init", "--no-fetch", "--no-commit")
+	if res.Version != "v1.2.3" {
+		t.Errorf("version = %v, want v1.2.3", res.Version)
+	}
+	if r.fake.Fetches != 0 {
+		t.Errorf("fetched %v time(s) despite --no-fetch", r.fake.Fetches)
+	}
+	if !strings.Contains(r.read(config.Filename), `"noCommit": true`) {
+		t.Errorf("noCommit not saved:\n%v", r.read(config.Filename))
+	}
+}
+
+func TestInitVCSError(t *testing.T) {
+	r := newFakeRepo(t)
+	r.fake.Err = errFake
+
+	if _, err := r.run("init", "--persist"); !errors.Is(err, errFake) {
+		t.Errorf("got %v, want %v", err, errFake)
+	}
+}
+
+func TestSync(t *testing.T) {
+	r := newFakeRepo(t)
+	r.release("1.0.0")
+	r.commit("Hotfix", map[string]string{"main.go": "package main\n"})
+	r.tag("v1.0.1")
+
+	res := r.mustRun("sync")
+	if res.OldVersion != "v1.0.0" || res.NewVersion != "v1.0.1" {
+		t.Errorf("synced %v -> %v, want v1.0.0 -> v1.0.1", res.OldVersion, res.NewVersion)
+	}
+	if res := r.mustRun("version"); res.Version != "v1.0.1" {
+		t.Errorf("version = %v, want v1.0.1", res.Version)
+	}
+}
+
+func TestSyncWithoutTags(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--persist")
+
+	if _, err := r.run("sync"); err == nil {
+		t.Error("expected error without tags")
+	}
+}
+
+func TestSubdirectory(t *testing.T) {
+	r := newFakeRepo(t)
+	if err := os.Mkdir(filepath.Join(r.dir, ".git"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	r.mustRun("init", "--persist")
+	r.write("docs/NOTES.md", "# Notes\n\n## 0.2.0\n\nWritten in docs\n")
+	sub := filepath.Join(r.dir, "docs")
+
+	// the config is found upwards, and paths typed on the command line are
+	// relative to the directory given
+	if res, err := r.runAt(sub, "bump", "--minor"); err != nil || res.NewVersion != "v0.2.0" {
+		t.Fatalf("bump = %+v (%v)", res, err)
+	}
+	if !strings.Contains(r.read("version.json"), `"minor":2`) {
+		t.Errorf("version.json not bumped at the top-level directory:\n%v", r.read("version.json"))
+	}
+
+	res, err := r.runAt(sub, "tag", "--changelog-name", "NOTES.md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tags := r.fake.TagList; len(tags) != 1 || !strings.Contains(tags[0].Message, "Written in docs") {
+		t.Errorf("unexpected tags: %+v", tags)
+	}
+	if res.Tag != "v0.2.0" {
+		t.Errorf("unexpected tag: %+v", res)
+	}
+
+	if res, err = r.runAt(sub, "show", "--changelog-name", "NOTES.md", "v0.2.0"); err != nil {
+		t.Fatal(err)
+	}
+	if res.Release == nil || res.Release.ChangeLog != "Written in docs" {
+		t.Errorf("unexpected release: %+v", res.Release)
+	}
+
+	if res := r.mustRun("config", "--persist"); res.Commit != r.head() {
+		t.Errorf("config not committed: %+v", res)
+	}
+	if _, err = r.runAt(sub, "status"); err != nil {
+		t.Errorf("status: %v", err)
+	}
+}
+
+func TestBeforeError(t *testing.T) {
+	r := newFakeRepo(t)
+
+	res, err := r.runAt(filepath.Join(r.dir, "missing"), "version")
+	if err == nil {
+		t.Fatal("expected error for a missing directory")
+	}
+	if res.Command != "version" || res.Error != err.Error() {
+		t.Errorf("unexpected JSON output: %+v", res)
+	}
+}
+
+func TestMissingConfig(t *testing.T) {
+	r := newFakeRepo(t)
+
+	for _, cmd := range []string{"version", "bump", "tag", "sync", "history", "audit", "ldflags"} {
+		if _, err := r.run(cmd); !errors.Is(err, config.ErrNotFound) {
+			t.Errorf("%v: got %v, want %v", cmd, err, config.ErrNotFound)
+		}
+	}
+}
@@ -3,6 +3,8 @@ package task
 import (
 	"context"
 	"fmt"
+	"strings"
+	"text/template"
 
 	"github.com/jwmwalrus/bumpy/internal/config"
 	"github.com/jwmwalrus/bumpy/version"
@@ -16,8 +18,8 @@ func Version() *cli.Command {
 		Aliases:         []string{"v"},
 		Category:        "Informational",
 		Usage:           "Display version",
-		UsageText:       "version [--short|--long]",
-		Description:     "Displays the current version for the repository",
+		UsageText:       "version [--short|--long|--format TEMPLATE]",
+		Description:     "Displays the current version for the repository. The --format flag takes a Go template, whose data is the version (.Major, .Minor, .Patch, .Pre, .Build) and whose functions are: tag, semver, pep440, debian, nextMajor, nextMinor, nextPatch, sha, shortSha, trimPrefix, replace, upper and lower",
 		SkipFlagParsing: false,
 		HideHelp:        false,
 		Hidden:          false,
@@ -37,27 +39,44 @@ func Version() *cli.Command {
 				Name:  "no-prefix",
 				Usage: "Remove v from the beginning of the version string",
 			},
+			&cli.StringFlag{
+				Name:    "format",
+				Aliases: []string{"f"},
+				Usage:   "Format the version according to the given Go `TEMPLATE`, e.g.: '{{.Major}}.{{.Minor}}'",
+			},
 		},
 	}
 }
 
 func versionAction(ctx context.Context, c *cli.Command) (err error) {
+	o := newOutput(ctx, c)
+	defer o.Flush(&err)
+
 	cfg, err := config.Load()
 	if err != nil {
 		return
 	}
 
 	v := version.Version{}
-	if err = v.LoadFrom(cfg.VersionPrefix); err != nil {
+	if err = v.LoadFrom(cfg.Path(cfg.VersionPrefix)); err != nil {
 		return
 	}
 
-	if c.Bool("short") {
+	o.SetVersion(v)
+
+	if c.String("format") != "" {
+		var str string
+		if str, err = formatVersion(cfg, v, c.String("format")); err != nil {
+			return
+		}
+		o.res.Formatted = str
+		o.Println(str)
+	} else if c.Bool("short") {
 		str := v.String()
 		if c.Bool("no-prefix") {
-			str = str[1:]
+			str = v.StringNoV()
 		}
-		fmt.Printf("%v\n", str)
+		o.Println(str)
 	} else if c.Bool("long") {
 		extra := ""
 		if v.Pre != "" {
@@ -67,14 +86,70 @@ func versionAction(ctx context.Context, c *cli.Command) (err error) {
 			extra += "\n\tBuild: " + v.Build
 		}
 
-		fmt.Printf("\nVersion: %v\n\tMajor: %v\n\tMinor: %v\n\tPatch: %v%v\n", v.String(), v.Major, v.Minor, v.Patch, extra)
+		o.Println(fmt.Sprintf("\nVersion: %v\n\tMajor: %v\n\tMinor: %v\n\tPatch: %v%v", v.String(), v.Major, v.Minor, v.Patch, extra))
 	} else {
 		str := v.String()
 		if c.Bool("no-prefix") {
-			str = str[1:]
+			str = v.StringNoV()
 		}
-		fmt.Printf("\nVersion: %v\n", str)
+		o.Println(fmt.Sprintf("\nVersion: %v", str))
 	}
 
 	return
 }
+
+func formatVersion(cfg *config.Config, v version.Version, format string) (string, error) {
+	var sha string
+	headSHA := func() (string, error) {
+		if sha != "" {
+			return sha, nil
+		}
+
+		var err error
+		sha, _, err = headCommit(cfg)
+		return sha, err
+	}
+
+	funcs := template.FuncMap{
+		"tag":    v.String,
+		"semver": v.StringNoV,
+		"pep440": v.PEP440,
+		"debian": v.Debian,
+		"nextMajor": func() *version.Version {
+			next := v.NextMajor()
+			return &next
+		},
+		"nextMinor": func() *version.Version {
+			next := v.NextMinor()
+			return &next
+		},
+		"nextPatch": func() *version.Version {
+			next := v.NextPatch()
+			return &next
+		},
+		"sha": headSHA,
+		"shortSha": func() (string, error) {
+			s, err := headSHA()
+			if len(s) > 7 {
+				s = s[:7]
+			}
+			return s, err
+		},
+		"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+		"replace":    func(old, new, s string) string { return strings.ReplaceAll(s, old, new) },
+		"upper":      strings.ToUpper,
+		"lower":      strings.ToLower,
+	}
+
+	tmpl, err := template.New("format").Funcs(funcs).Parse(format)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err = tmpl.Execute(&sb, &v); err != nil {
+		return "", err
+	}
+
+	return sb.String(), nil
+}
@@ -0,0 +1,41 @@
+package task
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/version"
+)
+
+func TestVersion(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--no-commit")
+	r.mustRun("bump", "--pre", "rc.1", "1.2.3")
+
+	res := r.mustRun("version")
+	if res.Version != "v1.2.3-rc.1" || res.Details == nil || res.Details.Pre != "rc.1" {
+		t.Errorf("unexpected version: %v, %+v", res.Version, res.Details)
+	}
+
+	res = r.mustRun("version", "--format", "{{.Major}}.{{.Minor}}-{{.Pre}}")
+	if res.Formatted != "1.2-rc.1" {
+		t.Errorf("formatted = %q, want %q", res.Formatted, "1.2-rc.1")
+	}
+}
+
+func TestVersionErrors(t *testing.T) {
+	r := newFakeRepo(t)
+	r.mustRun("init", "--no-commit")
+
+	if _, err := r.run("version", "--format", "{{.Missing}}"); err == nil {
+		t.Error("expected error for an invalid template")
+	}
+
+	if err := os.Remove(filepath.Join(r.dir, version.Filename)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.run("version"); err == nil {
+		t.Error("expected error without a version file")
+	}
+}
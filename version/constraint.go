@@ -0,0 +1,210 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// comparator is a single version comparison
+type comparator struct {
+	op string
+	v  Version
+}
+
+// Constraint is a version range, as a disjunction of sets of comparators
+// that must all be satisfied
+type Constraint struct {
+	str  string
+	sets [][]comparator
+}
+
+// ParseConstraint parses a version range, such as `>=1.2.0 <2.0.0`,
+// `^1.2`, `~1.2.3`, `1.x` or `1.2.3 || >=2.1.0`. Comparators may be
+// separated by spaces or commas, and alternatives by `||`
+func ParseConstraint(s string) (c Constraint, err error) {
+	c.str = s
+
+	for _, alt := range strings.Split(s, "||") {
+		fields := strings.Fields(strings.ReplaceAll(alt, ",", " "))
+		if len(fields) == 0 {
+			err = fmt.Errorf("Empty version constraint in `%v`", s)
+			return
+		}
+
+		var set []comparator
+		for i := 0; i < len(fields); i++ {
+			f := fields[i]
+			if isOperator(f) && i+1 < len(fields) {
+				f += fields[i+1]
+				i++
+			}
+
+			var cmps []comparator
+			if cmps, err = parseComparator(f); err != nil {
+				return
+			}
+			set = append(set, cmps...)
+		}
+		c.sets = append(c.sets, set)
+	}
+
+	return
+}
+
+// Check checks if the given version satisfies the constraint
+func (c Constraint) Check(v Version) bool {
+	for _, set := range c.sets {
+		ok := true
+		for _, cmp := range set {
+			if !cmp.check(v) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c Constraint) String() string {
+	return c.str
+}
+
+func (cmp comparator) check(v Version) bool {
+	d := v.Compare(cmp.v)
+	switch cmp.op {
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	case "!=":
+		return d != 0
+	}
+	return d == 0
+}
+
+func isOperator(s string) bool {
+	switch s {
+	case "=", "==", "!=", ">", ">=", "<", "<=", "^", "~":
+		return true
+	}
+	return false
+}
+
+// parseComparator parses a single comparator, expanding partial versions,
+// wildcards, carets and tildes into a range
+func parseComparator(s string) (list []comparator, err error) {
+	op := ""
+	for _, p := range []string{">=", "<=", "!=", "==", ">", "<", "=", "^", "~"} {
+		if strings.HasPrefix(s, p) {
+			op = p
+			s = strings.TrimSpace(s[len(p):])
+			break
+		}
+	}
+
+	v, parts, err := parsePartial(s)
+	if err != nil {
+		return
+	}
+
+	lower := comparator{">=", v}
+	switch op {
+	case "^":
+		upper := v.NextMajor()
+		if v.Major == 0 && parts > 1 {
+			upper = v.NextMinor()
+			if v.Minor == 0 && parts > 2 {
+				upper = v.NextPatch()
+			}
+		}
+		list = []comparator{lower, {"<", below(upper)}}
+	case "~":
+		upper := v.NextMinor()
+		if parts == 1 {
+			upper = v.NextMajor()
+		}
+		list = []comparator{lower, {"<", below(upper)}}
+	case "", "=", "==":
+		switch parts {
+		case 0:
+			list = []comparator{{">=", Version{}}}
+		case 1:
+			list = []comparator{lower, {"<", below(v.NextMajor())}}
+		case 2:
+			list = []comparator{lower, {"<", below(v.NextMinor())}}
+		default:
+			list = []comparator{{"=", v}}
+		}
+	default:
+		if parts < 3 && (op == ">" || op == "<=") {
+			// e.g., `>1.2` means `>=1.3.0`, and `<=1.2` means `<1.3.0`
+			next := v.NextMajor()
+			if parts == 2 {
+				next = v.NextMinor()
+			}
+			if op == ">" {
+				list = []comparator{{">=", next}}
+			} else {
+				list = []comparator{{"<", below(next)}}
+			}
+			return
+		}
+		if op == "<" && v.Pre == "" {
+			v = below(v)
+		}
+		list = []comparator{{op, v}}
+	}
+
+	return
+}
+
+// below returns the lowest prerelease of the given version, so that an
+// exclusive upper bound such as `<2.0.0` also excludes `2.0.0-rc.1`
+func below(v Version) Version {
+	v.Pre = "0"
+	return v
+}
+
+// parsePartial parses a possibly partial version (e.g., `1`, `1.2`, `1.x`),
+// returning the number of numeric parts given
+func parsePartial(s string) (v Version, parts int, err error) {
+	s = strings.TrimPrefix(s, "v")
+	if s == "" || s == "*" || s == "x" || s == "X" {
+		return
+	}
+
+	if strings.ContainsAny(s, "-+") {
+		err = v.Parse(s)
+		parts = 3
+		return
+	}
+
+	nums := []*int{&v.Major, &v.Minor, &v.Patch}
+	for i, p := range strings.Split(s, ".") {
+		if i > 2 {
+			err = fmt.Errorf("Invalid version in constraint: %v", s)
+			return
+		}
+		if p == "*" || p == "x" || p == "X" {
+			break
+		}
+
+		var n int
+		if n, err = strconv.Atoi(p); err != nil {
+			err = fmt.Errorf("Invalid version in constraint: %v", s)
+			return
+		}
+		*nums[i] = n
+		parts = i + 1
+	}
+
+	return
+}
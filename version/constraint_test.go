@@ -0,0 +1,68 @@
+package version
+
+import "testing"
+
+func TestParseConstraint(t *testing.T) {
+	tests := []struct {
+		constraint string
+		match      []string
+		noMatch    []string
+	}{
+		{"1.x", []string{"1.0.0", "1.9.9", "1.2.0-rc.1"}, []string{"0.9.9", "2.0.0", "2.0.0-rc.1", "2.0.0-0", "1.0.0-rc.1"}},
+		{"1", []string{"1.0.0", "1.5.2"}, []string{"2.0.0-alpha", "0.1.0"}},
+		{"1.2", []string{"1.2.0", "1.2.9"}, []string{"1.3.0-beta", "1.3.0", "1.1.9"}},
+		{"1.2.3", []string{"1.2.3"}, []string{"1.2.4", "1.2.3-rc.1"}},
+		{"*", []string{"0.0.0", "5.0.0"}, nil},
+		{"^1.2", []string{"1.2.0", "1.9.0"}, []string{"2.0.0-rc.1", "2.0.0", "1.1.0"}},
+		{"^1.2.3", []string{"1.2.3", "1.99.0"}, []string{"1.2.2", "2.0.0-0"}},
+		{"^0.2.3", []string{"0.2.3", "0.2.9"}, []string{"0.3.0-rc.1", "0.3.0"}},
+		{"^0.0.3", []string{"0.0.3"}, []string{"0.0.4-rc.1", "0.0.4"}},
+		{"~1.2", []string{"1.2.0", "1.2.7"}, []string{"1.3.0-rc.1", "1.3.0"}},
+		{"~1.2.3", []string{"1.2.3", "1.2.10"}, []string{"1.2.2", "1.3.0-alpha"}},
+		{"~1", []string{"1.0.0", "1.9.0"}, []string{"2.0.0-rc.1"}},
+		{">1.2", []string{"1.3.0", "2.0.0"}, []string{"1.2.9", "1.3.0-rc.1"}},
+		{"<=1.2", []string{"1.2.9", "0.1.0"}, []string{"1.3.0-rc.1", "1.3.0"}},
+		{"<2.0.0", []string{"1.9.9", "1.9.9-rc.1"}, []string{"2.0.0-rc.1", "2.0.0-0", "2.0.0"}},
+		{"<2.0.0-rc.2", []string{"2.0.0-rc.1"}, []string{"2.0.0-rc.2", "2.0.0"}},
+		{">=1.2.0 <2.0.0", []string{"1.2.0", "1.9.9"}, []string{"1.1.9", "2.0.0-rc.1", "2.0.0"}},
+		{">= 1.2.0, < 2.0.0", []string{"1.5.0"}, []string{"2.1.0"}},
+		{"!=1.2.3", []string{"1.2.4"}, []string{"1.2.3"}},
+		{"1.2.3 || >=2.1.0", []string{"1.2.3", "2.1.0", "3.0.0"}, []string{"1.2.4", "2.0.0"}},
+		{"v1.2.3-rc.1", []string{"1.2.3-rc.1"}, []string{"1.2.3"}},
+	}
+
+	for _, tt := range tests {
+		c, err := ParseConstraint(tt.constraint)
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.constraint, err)
+			continue
+		}
+
+		for _, s := range tt.match {
+			if !c.Check(mustParse(t, s)) {
+				t.Errorf("%v should match %v", tt.constraint, s)
+			}
+		}
+		for _, s := range tt.noMatch {
+			if c.Check(mustParse(t, s)) {
+				t.Errorf("%v should not match %v", tt.constraint, s)
+			}
+		}
+	}
+}
+
+func TestParseConstraintErrors(t *testing.T) {
+	for _, s := range []string{"", "||", "1.2.3.4", "a.b", "1 || "} {
+		if _, err := ParseConstraint(s); err == nil {
+			t.Errorf("%q: expected error", s)
+		}
+	}
+}
+
+func mustParse(t *testing.T, s string) (v Version) {
+	t.Helper()
+	if err := v.Parse(s); err != nil {
+		t.Fatalf("%v: %v", s, err)
+	}
+	return
+}
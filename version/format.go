@@ -0,0 +1,88 @@
+package version
+
+import (
+	"strconv"
+	"strings"
+)
+
+// pep440Pre maps prerelease labels to their PEP 440 counterparts
+var pep440Pre = map[string]string{
+	"a":       "a",
+	"alpha":   "a",
+	"b":       "b",
+	"beta":    "b",
+	"c":       "rc",
+	"rc":      "rc",
+	"pre":     "rc",
+	"preview": "rc",
+	"dev":     ".dev",
+}
+
+// PEP440 returns the version string in the form prescribed by PEP 440, for
+// Python packages (e.g., `1.2.0rc1`, `1.2.0.dev3`, `1.2.0+build.5`).
+// Unknown prerelease labels --including `post`, since a PEP 440 post-release
+// sorts after the release-- are mapped to a development release carrying the
+// original label as local version
+func (v *Version) PEP440() string {
+	out := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+
+	var local []string
+	if v.Pre != "" {
+		label, num := splitPreLabel(v.Pre)
+		if mapped, ok := pep440Pre[strings.ToLower(label)]; ok {
+			out += mapped + strconv.Itoa(num)
+		} else {
+			out += ".dev" + strconv.Itoa(num)
+			local = append(local, localSegment(v.Pre))
+		}
+	}
+
+	if v.Build != "" {
+		local = append(local, localSegment(v.Build))
+	}
+
+	if len(local) > 0 {
+		out += "+" + strings.Join(local, ".")
+	}
+
+	return out
+}
+
+// Debian returns the version string in the form used for Debian upstream
+// versions, where prereleases sort before the release (e.g., `1.2.0~rc.1`)
+func (v *Version) Debian() string {
+	out := strconv.Itoa(v.Major) + "." + strconv.Itoa(v.Minor) + "." + strconv.Itoa(v.Patch)
+
+	if v.Pre != "" {
+		out += "~" + strings.ReplaceAll(v.Pre, "-", ".")
+	}
+
+	if v.Build != "" {
+		out += "+" + strings.ReplaceAll(v.Build, "-", ".")
+	}
+
+	return out
+}
+
+// splitPreLabel splits a prerelease string into its leading label and
+// trailing number (e.g., `rc.2` -> `rc`, 2; `beta3` -> `beta`, 3)
+func splitPreLabel(pre string) (label string, num int) {
+	label = pre
+	if i := strings.LastIndexFunc(pre, func(r rune) bool { return r < '0' || r > '9' }); i < len(pre)-1 {
+		label = pre[:i+1]
+		num, _ = strconv.Atoi(pre[i+1:])
+	}
+
+	label = strings.TrimRight(label, ".-_")
+	return
+}
+
+func localSegment(s string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= '0' && r <= '9', r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			return r
+		}
+		return '.'
+	}, s)
+}
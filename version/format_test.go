@@ -0,0 +1,44 @@
+package version
+
+import "testing"
+
+func TestPEP440(t *testing.T) {
+	tests := []struct {
+		version string
+		want    string
+	}{
+		{"1.2.0", "1.2.0"},
+		{"1.2.0-rc.1", "1.2.0rc1"},
+		{"1.2.0-beta3", "1.2.0b3"},
+		{"1.2.0-alpha", "1.2.0a0"},
+		{"1.2.0-dev.3", "1.2.0.dev3"},
+		{"1.2.0-post.1", "1.2.0.dev1+post.1"},
+		{"1.2.0-feature-x.2", "1.2.0.dev2+feature.x.2"},
+		{"1.2.0+build.5", "1.2.0+build.5"},
+		{"1.2.0-rc.1+build.5", "1.2.0rc1+build.5"},
+	}
+
+	for _, tt := range tests {
+		v := mustParse(t, tt.version)
+		if got := v.PEP440(); got != tt.want {
+			t.Errorf("%v: PEP440() = %q, want %q", tt.version, got, tt.want)
+		}
+	}
+}
+
+func TestDebian(t *testing.T) {
+	tests := []struct {
+		v    Version
+		want string
+	}{
+		{Version{Major: 1, Minor: 2}, "1.2.0"},
+		{Version{Major: 1, Minor: 2, Pre: "rc.1"}, "1.2.0~rc.1"},
+		{Version{Major: 1, Minor: 2, Pre: "post-1", Build: "build-5"}, "1.2.0~post.1+build.5"},
+	}
+
+	for _, tt := range tests {
+		if got := tt.v.Debian(); got != tt.want {
+			t.Errorf("%+v: Debian() = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package version
+
+import (
+	"bytes"
+	"encoding/json"
+	"expvar"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MetricName is the default name of the build info metric
+	MetricName = "build_info"
+
+	metricsContentType = "text/plain; version=0.0.4; charset=utf-8"
+)
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// ServeHTTP implements the http.Handler interface, serving the Info as JSON,
+// e.g.:
+//
+//	http.Handle("/version", info)
+func (info Info) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !allowGet(w, r) {
+		return
+	}
+
+	bv, err := json.Marshal(info)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	serve(w, r, "application/json", bv)
+}
+
+// PublishExpvar publishes the Info under the given expvar name, which must be
+// unique, as expvar.Publish panics otherwise
+func (info Info) PublishExpvar(name string) {
+	expvar.Publish(name, expvar.Func(func() any { return info }))
+}
+
+// WriteMetric writes a Prometheus-style gauge with the given name (MetricName
+// if empty), a constant value of 1 and the version and build info as labels,
+// in the text exposition format
+func (info Info) WriteMetric(w io.Writer, name string) error {
+	if name == "" {
+		name = MetricName
+	}
+
+	labels := [][2]string{
+		{"version", info.Version.String()},
+		{"major", strconv.Itoa(info.Version.Major)},
+		{"minor", strconv.Itoa(info.Version.Minor)},
+		{"patch", strconv.Itoa(info.Version.Patch)},
+		{"revision", info.Revision},
+		{"modified", strconv.FormatBool(info.Modified)},
+		{"goversion", info.GoVersion},
+		{"path", info.Path},
+	}
+
+	var pairs []string
+	for _, l := range labels {
+		pairs = append(pairs, l[0]+`="`+labelEscaper.Replace(l[1])+`"`)
+	}
+
+	_, err := fmt.Fprintf(w,
+		"# HELP %[1]v A metric with a constant '1' value labeled by version and build info.\n"+
+			"# TYPE %[1]v gauge\n"+
+			"%[1]v{%[2]v} 1\n",
+		name, strings.Join(pairs, ","))
+	return err
+}
+
+// MetricHandler returns an http.Handler serving the metric written by
+// WriteMetric, e.g.:
+//
+//	http.Handle("/metrics", info.MetricHandler(""))
+func (info Info) MetricHandler(name string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !allowGet(w, r) {
+			return
+		}
+
+		var buf bytes.Buffer
+		if err := info.WriteMetric(&buf, name); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		serve(w, r, metricsContentType, buf.Bytes())
+	})
+}
+
+// allowGet answers with 405 Method Not Allowed to anything but GET and HEAD
+// requests, and tells whether the request is allowed
+func allowGet(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodGet || r.Method == http.MethodHead {
+		return true
+	}
+
+	w.Header().Set("Allow", "GET, HEAD")
+	http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+	return false
+}
+
+// serve writes the given body, omitted for HEAD requests
+func serve(w http.ResponseWriter, r *http.Request, contentType string, bv []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(bv)))
+	if r.Method == http.MethodGet {
+		w.Write(bv)
+	}
+}
@@ -0,0 +1,89 @@
+package version
+
+import (
+	"encoding/json"
+	"expvar"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestServeHTTP(t *testing.T) {
+	info := testInfo()
+	handlers := map[string]http.Handler{
+		"info":   info,
+		"metric": info.MetricHandler(""),
+	}
+
+	for name, h := range handlers {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+		if rec.Code != http.StatusOK || rec.Body.Len() == 0 {
+			t.Errorf("%v: GET = %v, %q", name, rec.Code, rec.Body.String())
+		}
+		size := rec.Header().Get("Content-Length")
+
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodHead, "/", nil))
+		if rec.Code != http.StatusOK || rec.Body.Len() != 0 || rec.Header().Get("Content-Length") != size {
+			t.Errorf("%v: HEAD = %v, %q, length %v", name, rec.Code, rec.Body.String(), rec.Header().Get("Content-Length"))
+		}
+
+		for _, method := range []string{http.MethodPost, http.MethodPut, http.MethodDelete} {
+			rec = httptest.NewRecorder()
+			h.ServeHTTP(rec, httptest.NewRequest(method, "/", nil))
+			if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, HEAD" {
+				t.Errorf("%v: %v = %v, allow %q", name, method, rec.Code, rec.Header().Get("Allow"))
+			}
+		}
+	}
+
+	rec := httptest.NewRecorder()
+	info.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/version", nil))
+	if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+		t.Errorf("content type = %v", ct)
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil || got["version"] != "v1.2.3-rc.1" {
+		t.Errorf("body = %s (%v)", rec.Body.Bytes(), err)
+	}
+}
+
+func TestWriteMetric(t *testing.T) {
+	info := testInfo()
+	info.Path = "example.com/\"quoted\"\\path\nnext"
+
+	var sb strings.Builder
+	if err := info.WriteMetric(&sb, "app_build_info"); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "# HELP app_build_info A metric with a constant '1' value labeled by version and build info.\n" +
+		"# TYPE app_build_info gauge\n" +
+		`app_build_info{version="v1.2.3-rc.1",major="1",minor="2",patch="3",` +
+		`revision="0123456789abcdef0123456789abcdef01234567",modified="true",goversion="go1.24.2",` +
+		`path="example.com/\"quoted\"\\path\nnext"} 1` + "\n"
+	if sb.String() != want {
+		t.Errorf("metric =\n%v\nwant\n%v", sb.String(), want)
+	}
+
+	rec := httptest.NewRecorder()
+	info.MetricHandler("").ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if !strings.Contains(rec.Body.String(), "\n"+MetricName+"{") {
+		t.Errorf("default metric name missing:\n%v", rec.Body.String())
+	}
+	if ct := rec.Header().Get("Content-Type"); ct != metricsContentType {
+		t.Errorf("content type = %v", ct)
+	}
+}
+
+func TestPublishExpvar(t *testing.T) {
+	testInfo().PublishExpvar("version_test")
+
+	v := expvar.Get("version_test")
+	if v == nil || !strings.Contains(v.String(), `"version":"v1.2.3-rc.1"`) {
+		t.Errorf("published %v", v)
+	}
+}
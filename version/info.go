@@ -0,0 +1,176 @@
+package version
+
+import (
+	"encoding/json"
+	"fmt"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Dependency describes a module the binary was built with
+type Dependency struct {
+	Path    string `json:"path"`
+	Version string `json:"version"`
+	Replace string `json:"replace,omitempty"`
+}
+
+// Info combines the embedded version with the build information recorded in
+// the binary
+type Info struct {
+	Version   Version
+	VCS       string
+	Revision  string
+	Time      time.Time
+	Modified  bool
+	GoVersion string
+	Path      string
+	Deps      []Dependency
+}
+
+// infoJSON is the JSON representation of Info
+type infoJSON struct {
+	Version   string       `json:"version"`
+	Major     int          `json:"major"`
+	Minor     int          `json:"minor"`
+	Patch     int          `json:"patch"`
+	Pre       string       `json:"pre,omitempty"`
+	Build     string       `json:"build,omitempty"`
+	VCS       string       `json:"vcs,omitempty"`
+	Revision  string       `json:"revision,omitempty"`
+	Time      *time.Time   `json:"time,omitempty"`
+	Modified  bool         `json:"modified"`
+	GoVersion string       `json:"goVersion,omitempty"`
+	Path      string       `json:"path,omitempty"`
+	Deps      []Dependency `json:"deps,omitempty"`
+}
+
+// NewInfo returns the Info for the given version.json bytes, usually
+// embedded in the binary. Missing build or VCS information is not an error
+func NewInfo(b []byte) (info Info, err error) {
+	if err = info.Version.Read(b); err != nil {
+		return
+	}
+
+	info.readBuildInfo()
+	return
+}
+
+func (info *Info) readBuildInfo() {
+	bi, ok := debug.ReadBuildInfo()
+	if !ok {
+		return
+	}
+
+	info.GoVersion = bi.GoVersion
+	info.Path = bi.Main.Path
+
+	for _, s := range bi.Settings {
+		switch s.Key {
+		case "vcs":
+			info.VCS = s.Value
+		case "vcs.revision":
+			info.Revision = s.Value
+		case "vcs.time":
+			info.Time, _ = time.Parse(time.RFC3339, s.Value)
+		case "vcs.modified":
+			info.Modified = s.Value == "true"
+		}
+	}
+
+	for _, d := range bi.Deps {
+		dep := Dependency{Path: d.Path, Version: d.Version}
+		if d.Replace != nil {
+			dep.Replace = d.Replace.Path
+			if d.Replace.Version != "" {
+				dep.Replace += "@" + d.Replace.Version
+			}
+		}
+		info.Deps = append(info.Deps, dep)
+	}
+}
+
+// ShortRevision returns the first 12 characters of the revision
+func (info Info) ShortRevision() string {
+	if len(info.Revision) > 12 {
+		return info.Revision[:12]
+	}
+	return info.Revision
+}
+
+// String returns the version, followed by the short revision and modified
+// state, if available (e.g., `v1.2.3 (0123456789ab, modified)`)
+func (info Info) String() string {
+	out := info.Version.String()
+	if info.Revision == "" {
+		return out
+	}
+
+	out += " (" + info.ShortRevision()
+	if info.Modified {
+		out += ", modified"
+	}
+	return out + ")"
+}
+
+// Long returns a detailed, human-readable, multi-line description
+func (info Info) Long() string {
+	var sb strings.Builder
+
+	line := func(label, value string) {
+		if value != "" {
+			fmt.Fprintf(&sb, "%-12s%v\n", label+":", value)
+		}
+	}
+
+	line("Version", info.Version.String())
+	line("Module", info.Path)
+	line("VCS", info.VCS)
+	line("Revision", info.Revision)
+	if !info.Time.IsZero() {
+		line("Time", info.Time.UTC().Format(time.RFC3339))
+	}
+	if info.Revision != "" {
+		line("Modified", strconv.FormatBool(info.Modified))
+	}
+	line("Go version", info.GoVersion)
+
+	if len(info.Deps) > 0 {
+		sb.WriteString("Dependencies:\n")
+		for _, d := range info.Deps {
+			fmt.Fprintf(&sb, "\t%v %v", d.Path, d.Version)
+			if d.Replace != "" {
+				fmt.Fprintf(&sb, " => %v", d.Replace)
+			}
+			sb.WriteString("\n")
+		}
+	}
+
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// MarshalJSON implements the json.Marshaler interface
+func (info Info) MarshalJSON() ([]byte, error) {
+	out := infoJSON{
+		Version:   info.Version.String(),
+		Major:     info.Version.Major,
+		Minor:     info.Version.Minor,
+		Patch:     info.Version.Patch,
+		Pre:       info.Version.Pre,
+		Build:     info.Version.Build,
+		VCS:       info.VCS,
+		Revision:  info.Revision,
+		Modified:  info.Modified,
+		GoVersion: info.GoVersion,
+		Path:      info.Path,
+		Deps:      info.Deps,
+	}
+
+	if !info.Time.IsZero() {
+		t := info.Time.UTC()
+		out.Time = &t
+	}
+
+	return json.Marshal(out)
+}
@@ -0,0 +1,105 @@
+package version
+
+import (
+	"encoding/json"
+	"runtime"
+	"testing"
+	"time"
+)
+
+// testInfo returns an Info with every field set
+func testInfo() Info {
+	return Info{
+		Version:   Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1"},
+		VCS:       "git",
+		Revision:  "0123456789abcdef0123456789abcdef01234567",
+		Time:      time.Date(2024, 5, 6, 7, 8, 9, 0, time.FixedZone("", 3600)),
+		Modified:  true,
+		GoVersion: "go1.24.2",
+		Path:      "example.com/app",
+		Deps: []Dependency{
+			{Path: "example.com/dep", Version: "v1.0.0"},
+			{Path: "example.com/fork", Version: "v2.0.0", Replace: "../fork"},
+		},
+	}
+}
+
+func TestNewInfo(t *testing.T) {
+	info, err := NewInfo([]byte(`{"major": 1, "minor": 2, "patch": 3, "pre": "", "build": "b5"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := info.Version.String(); got != "v1.2.3+b5" {
+		t.Errorf("version = %v, want v1.2.3+b5", got)
+	}
+
+	// the test binary carries build information, but no VCS stamp
+	if info.GoVersion != runtime.Version() {
+		t.Errorf("go version = %q, want %q", info.GoVersion, runtime.Version())
+	}
+
+	if _, err = NewInfo([]byte("{")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+}
+
+func TestInfoString(t *testing.T) {
+	info := testInfo()
+	if got, want := info.String(), "v1.2.3-rc.1 (0123456789ab, modified)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	info.Modified = false
+	if got, want := info.String(), "v1.2.3-rc.1 (0123456789ab)"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+
+	info.Revision = "abc"
+	if got := info.ShortRevision(); got != "abc" {
+		t.Errorf("ShortRevision() = %q, want abc", got)
+	}
+
+	if got := (Info{Version: info.Version}).String(); got != "v1.2.3-rc.1" {
+		t.Errorf("String() without revision = %q", got)
+	}
+}
+
+func TestInfoLong(t *testing.T) {
+	want := `Version:    v1.2.3-rc.1
+Module:     example.com/app
+VCS:        git
+Revision:   0123456789abcdef0123456789abcdef01234567
+Time:       2024-05-06T06:08:09Z
+Modified:   true
+Go version: go1.24.2
+Dependencies:
+	example.com/dep v1.0.0
+	example.com/fork v2.0.0 => ../fork`
+
+	if got := testInfo().Long(); got != want {
+		t.Errorf("Long() =\n%v\nwant\n%v", got, want)
+	}
+
+	if got := (Info{Version: New()}).Long(); got != "Version:    v0.1.0" {
+		t.Errorf("Long() without build info = %q", got)
+	}
+}
+
+func TestInfoJSON(t *testing.T) {
+	bv, err := json.Marshal(testInfo())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `{"version":"v1.2.3-rc.1","major":1,"minor":2,"patch":3,"pre":"rc.1","vcs":"git",` +
+		`"revision":"0123456789abcdef0123456789abcdef01234567","time":"2024-05-06T06:08:09Z","modified":true,` +
+		`"goVersion":"go1.24.2","path":"example.com/app","deps":[{"path":"example.com/dep","version":"v1.0.0"},` +
+		`{"path":"example.com/fork","version":"v2.0.0","replace":"../fork"}]}`
+	if string(bv) != want {
+		t.Errorf("JSON =\n%s\nwant\n%s", bv, want)
+	}
+
+	if bv, _ = json.Marshal(Info{Version: New()}); string(bv) != `{"version":"v0.1.0","major":0,"minor":1,"patch":0,"modified":false}` {
+		t.Errorf("JSON without build info = %s", bv)
+	}
+}
@@ -41,6 +41,25 @@ func (v *Version) Equals(r Version) bool {
 		v.Build == r.Build
 }
 
+// Compare compares two versions according to the SemVer precedence rules,
+// returning -1, 0 or +1. Build metadata is ignored
+func (v *Version) Compare(r Version) int {
+	for _, d := range []int{v.Major - r.Major, v.Minor - r.Minor, v.Patch - r.Patch} {
+		if d < 0 {
+			return -1
+		} else if d > 0 {
+			return 1
+		}
+	}
+
+	return comparePre(v.Pre, r.Pre)
+}
+
+// Less checks if the version has a lower precedence than the given one
+func (v *Version) Less(r Version) bool {
+	return v.Compare(r) < 0
+}
+
 // EqualsString checks if version is identical to string
 func (v *Version) EqualsString(s string) (ok bool, err error) {
 	var r Version
@@ -52,6 +71,65 @@ func (v *Version) EqualsString(s string) (ok bool, err error) {
 	return
 }
 
+// NextMajor returns the next major version
+func (v *Version) NextMajor() Version {
+	return Version{Major: v.Major + 1}
+}
+
+// NextMinor returns the next minor version
+func (v *Version) NextMinor() Version {
+	return Version{Major: v.Major, Minor: v.Minor + 1}
+}
+
+// NextPatch returns the next patch version
+func (v *Version) NextPatch() Version {
+	return Version{Major: v.Major, Minor: v.Minor, Patch: v.Patch + 1}
+}
+
+func comparePre(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+
+	as := strings.Split(a, ".")
+	bs := strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.ParseUint(as[i], 10, 64)
+		bn, bErr := strconv.ParseUint(bs[i], 10, 64)
+
+		switch {
+		case aErr == nil && bErr == nil:
+			if an != bn {
+				if an < bn {
+					return -1
+				}
+				return 1
+			}
+		case aErr == nil:
+			return -1
+		case bErr == nil:
+			return 1
+		default:
+			if c := strings.Compare(as[i], bs[i]); c != 0 {
+				return c
+			}
+		}
+	}
+
+	switch {
+	case len(as) < len(bs):
+		return -1
+	case len(as) > len(bs):
+		return 1
+	}
+	return 0
+}
+
 // Load loads the version file from the current working directory
 func (v *Version) Load() (err error) {
 	err = v.LoadFrom(".")
@@ -179,6 +257,8 @@ func (v *Version) StringNoV() (out string) {
 }
 
 // GetBuildInfo returns the embedded vcs-related information
+//
+// Deprecated: Use NewInfo, which does not fail for non-git builds
 func GetBuildInfo() (rev string, t time.Time, mod bool, err error) {
 	bi, ok := debug.ReadBuildInfo()
 	if !ok {
@@ -0,0 +1,122 @@
+// Package versioncli provides a ready-to-use version command for CLIs built
+// on urfave/cli v3 that embed a version.json file.
+package versioncli
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+// Format defines the output format of the version
+type Format int
+
+// Output formats
+const (
+	// Default prints the version along with the short revision
+	Default Format = iota
+
+	// Short prints only the version
+	Short
+
+	// Long prints the detailed, multi-line form
+	Long
+
+	// JSON prints the JSON document
+	JSON
+)
+
+// Command returns a `version` command for the given version.json bytes,
+// supporting the --short, --long and --json flags, e.g.:
+//
+//	//go:embed version.json
+//	var versionJSON []byte
+//	...
+//	Commands: []*cli.Command{versioncli.MustCommand(versionJSON)},
+func Command(b []byte) (*cli.Command, error) {
+	info, err := version.NewInfo(b)
+	if err != nil {
+		return nil, err
+	}
+
+	return CommandFor(info), nil
+}
+
+// MustCommand is like Command, but panics on error
+func MustCommand(b []byte) *cli.Command {
+	cmd, err := Command(b)
+	if err != nil {
+		panic(err)
+	}
+	return cmd
+}
+
+// CommandFor returns a `version` command for the given Info
+func CommandFor(info version.Info) *cli.Command {
+	return &cli.Command{
+		Name:            "version",
+		Usage:           "Display version",
+		UsageText:       "version [--short|--long|--json]",
+		Description:     "Displays the version of the application and its build information",
+		SkipFlagParsing: false,
+		HideHelp:        false,
+		Hidden:          false,
+		Flags: []cli.Flag{
+			&cli.BoolFlag{
+				Name:    "short",
+				Aliases: []string{"s"},
+				Usage:   "Short version",
+			},
+			&cli.BoolFlag{
+				Name:    "long",
+				Aliases: []string{"l"},
+				Usage:   "Long, detailed version",
+			},
+			&cli.BoolFlag{
+				Name:  "json",
+				Usage: "Version and build information as JSON",
+			},
+		},
+		Action: func(ctx context.Context, c *cli.Command) error {
+			format := Default
+			if c.Bool("short") {
+				format = Short
+			} else if c.Bool("long") {
+				format = Long
+			} else if c.Bool("json") {
+				format = JSON
+			}
+
+			return Print(c.Root().Writer, info, format)
+		},
+	}
+}
+
+// Printer returns a function suitable for cli.VersionPrinter, printing the
+// given Info in the given format
+func Printer(info version.Info, format Format) func(*cli.Command) {
+	return func(c *cli.Command) {
+		Print(c.Root().Writer, info, format)
+	}
+}
+
+// Print writes the given Info to w, in the given format
+func Print(w io.Writer, info version.Info, format Format) (err error) {
+	switch format {
+	case Short:
+		_, err = fmt.Fprintf(w, "%v\n", info.Version.String())
+	case Long:
+		_, err = fmt.Fprintf(w, "%v\n", info.Long())
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		err = enc.Encode(info)
+	default:
+		_, err = fmt.Fprintf(w, "%v\n", info.String())
+	}
+	return
+}
@@ -0,0 +1,100 @@
+package versioncli
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/jwmwalrus/bumpy/version"
+	"github.com/urfave/cli/v3"
+)
+
+const versionJSON = `{"major": 1, "minor": 2, "patch": 3, "pre": "rc.1", "build": ""}`
+
+// run runs the given command line on an application with the version command
+// and printer, and returns its output
+func run(t *testing.T, args ...string) string {
+	t.Helper()
+
+	var out bytes.Buffer
+	info := version.Info{
+		Version:  version.Version{Major: 1, Minor: 2, Patch: 3, Pre: "rc.1"},
+		Revision: "0123456789abcdef",
+		Modified: true,
+	}
+
+	cmd := &cli.Command{
+		Name:     "app",
+		Version:  info.Version.String(),
+		Commands: []*cli.Command{CommandFor(info)},
+		Writer:   &out,
+	}
+
+	printer := cli.VersionPrinter
+	cli.VersionPrinter = Printer(info, Short)
+	defer func() { cli.VersionPrinter = printer }()
+
+	if err := cmd.Run(context.Background(), append([]string{"app"}, args...)); err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+	return out.String()
+}
+
+func TestCommand(t *testing.T) {
+	tests := []struct {
+		args []string
+		want string
+	}{
+		{[]string{"version"}, "v1.2.3-rc.1 (0123456789ab, modified)\n"},
+		{[]string{"version", "--short"}, "v1.2.3-rc.1\n"},
+		{[]string{"version", "-s"}, "v1.2.3-rc.1\n"},
+		{[]string{"version", "--long"}, "Version:    v1.2.3-rc.1\nRevision:   0123456789abcdef\nModified:   true\n"},
+		{[]string{"--version"}, "v1.2.3-rc.1\n"},
+	}
+
+	for _, tt := range tests {
+		if got := run(t, tt.args...); got != tt.want {
+			t.Errorf("%v = %q, want %q", tt.args, got, tt.want)
+		}
+	}
+
+	var got map[string]any
+	if err := json.Unmarshal([]byte(run(t, "version", "--json")), &got); err != nil || got["version"] != "v1.2.3-rc.1" || got["revision"] != "0123456789abcdef" {
+		t.Errorf("--json = %v (%v)", got, err)
+	}
+}
+
+func TestMustCommand(t *testing.T) {
+	cmd := MustCommand([]byte(versionJSON))
+	if cmd.Name != "version" {
+		t.Errorf("name = %v", cmd.Name)
+	}
+
+	if _, err := Command([]byte("{")); err == nil {
+		t.Error("expected error for invalid JSON")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected panic for invalid JSON")
+		}
+	}()
+	MustCommand([]byte("{"))
+}
+
+func TestPrint(t *testing.T) {
+	info := version.Info{Version: version.New()}
+
+	for format, want := range map[Format]string{
+		Default: "v0.1.0\n",
+		Short:   "v0.1.0\n",
+		Long:    "Version:    v0.1.0\n",
+	} {
+		var sb strings.Builder
+		if err := Print(&sb, info, format); err != nil || sb.String() != want {
+			t.Errorf("format %v = %q (%v), want %q", format, sb.String(), err, want)
+		}
+	}
+}